@@ -0,0 +1,141 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package align
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/align"
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+const (
+	defaultSourceFile     = "example/source_vectors.txt"
+	defaultTargetFile     = "example/target_vectors.txt"
+	defaultDictionaryFile = ""
+	defaultEvalFile       = ""
+	defaultOutputFile     = "example/aligned_vectors.txt"
+)
+
+var (
+	defaultK = []int{1, 5, 10}
+)
+
+var (
+	sourceFile     string
+	targetFile     string
+	dictionaryFile string
+	evalFile       string
+	outputFile     string
+	k              []int
+)
+
+func New() *cobra.Command {
+	var opts align.Options
+	cmd := &cobra.Command{
+		Use:   "align",
+		Short: "Align word vectors to another space by orthogonal Procrustes",
+		Example: "  wego align -s example/source_vectors.txt -t example/target_vectors.txt -d example/seed.txt --eval example/test.txt\n" +
+			"  wego align -s example/2000s_vectors.txt -t example/2010s_vectors.txt -o example/aligned_vectors.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&sourceFile, "source", "s", defaultSourceFile, "input file path for source word vector to be mapped (in the binary format of word2vec for .bin)")
+	cmd.Flags().StringVarP(&targetFile, "target", "t", defaultTargetFile, "input file path for target word vector (in the binary format of word2vec for .bin)")
+	cmd.Flags().StringVarP(&dictionaryFile, "dictionary", "d", defaultDictionaryFile, "input file path for seed dictionary, a source and target word per line (use identical words if empty)")
+	cmd.Flags().StringVar(&evalFile, "eval", defaultEvalFile, "input file path for test dictionary to report precision@k (disabled if empty)")
+	cmd.Flags().IntSliceVarP(&k, "k", "k", defaultK, "k for precision@k")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save mapped source word vectors (in the binary format of word2vec for .bin, with the header line for .vec)")
+	align.LoadForCmd(cmd, &opts)
+	return cmd
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func loadDictionary(path string) (align.Dictionary, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return align.LoadDictionary(f)
+}
+
+func execute(opts align.Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if fileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+	for _, f := range []string{sourceFile, targetFile, dictionaryFile, evalFile} {
+		if f != "" && !fileExists(f) {
+			return errors.Errorf("Not such a file %s", f)
+		}
+	}
+
+	src, err := embedding.LoadFile(sourceFile)
+	if err != nil {
+		return err
+	}
+	tgt, err := embedding.LoadFile(targetFile)
+	if err != nil {
+		return err
+	}
+	dic, err := loadDictionary(dictionaryFile)
+	if err != nil {
+		return err
+	}
+	test, err := loadDictionary(evalFile)
+	if err != nil {
+		return err
+	}
+
+	mapping, err := align.AlignForOptions(src, tgt, dic, opts)
+	if err != nil {
+		return err
+	}
+	mapped := mapping.Apply(src)
+
+	if len(test) > 0 {
+		table := make([][]string, len(k))
+		for i, v := range k {
+			p, n, err := align.Precision(mapped, tgt, test, v)
+			if err != nil {
+				return err
+			}
+			table[i] = []string{fmt.Sprintf("%d", v), fmt.Sprintf("%f", p), fmt.Sprintf("%d", n)}
+		}
+		writer := tablewriter.NewWriter(os.Stdout)
+		writer.SetHeader([]string{"K", "Precision", "Words"})
+		writer.SetBorder(false)
+		writer.AppendBulk(table)
+		writer.Render()
+	}
+
+	return embedding.SaveFile(outputFile, mapped)
+}
@@ -0,0 +1,101 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd builds the wego command. A program which imports the packages of other
+// models, which call model.Register in their init, gets them in `wego train --model`.
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/align"
+	"github.com/ynqa/wego/cmd/conllu"
+	"github.com/ynqa/wego/cmd/model/glove"
+	"github.com/ynqa/wego/cmd/model/lexvec"
+	"github.com/ynqa/wego/cmd/model/svd"
+	"github.com/ynqa/wego/cmd/model/swivel"
+	"github.com/ynqa/wego/cmd/model/word2vec"
+	"github.com/ynqa/wego/cmd/model/word2vecf"
+	"github.com/ynqa/wego/cmd/query"
+	"github.com/ynqa/wego/cmd/query/console"
+	"github.com/ynqa/wego/cmd/retrofit"
+	"github.com/ynqa/wego/cmd/sweep"
+	"github.com/ynqa/wego/cmd/train"
+	"github.com/ynqa/wego/cmd/transform"
+	"github.com/ynqa/wego/pkg/config"
+)
+
+// New returns the root command of wego.
+func New() *cobra.Command {
+	word2vec := word2vec.New()
+	glove := glove.New()
+	lexvec := lexvec.New()
+	swivel := swivel.New()
+	svd := svd.New()
+	word2vecf := word2vecf.New()
+	train := train.New()
+	sweep := sweep.New()
+	conllu := conllu.New()
+	query := query.New()
+	console := console.New()
+	retrofit := retrofit.New()
+	transform := transform.New()
+	align := align.New()
+
+	cmd := &cobra.Command{
+		Use:   "wego",
+		Short: "tools for embedding words into vector space",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.Errorf("Set sub-command. One of %s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s|%s",
+				word2vec.Name(),
+				glove.Name(),
+				lexvec.Name(),
+				swivel.Name(),
+				svd.Name(),
+				word2vecf.Name(),
+				train.Name(),
+				sweep.Name(),
+				conllu.Name(),
+				query.Name(),
+				console.Name(),
+				retrofit.Name(),
+				transform.Name(),
+				align.Name(),
+			)
+		},
+	}
+	cmd.AddCommand(word2vec)
+	cmd.AddCommand(glove)
+	cmd.AddCommand(lexvec)
+	cmd.AddCommand(swivel)
+	cmd.AddCommand(svd)
+	cmd.AddCommand(word2vecf)
+	cmd.AddCommand(train)
+	cmd.AddCommand(sweep)
+	cmd.AddCommand(conllu)
+	cmd.AddCommand(query)
+	cmd.AddCommand(console)
+	cmd.AddCommand(retrofit)
+	cmd.AddCommand(transform)
+	cmd.AddCommand(align)
+
+	for _, sub := range cmd.Commands() {
+		// train and sweep bind the config to the command of the model.
+		if sub.RunE != nil && !sub.DisableFlagParsing {
+			config.Bind(sub)
+		}
+	}
+	return cmd
+}
@@ -0,0 +1,89 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conllu
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/corpus/conllu"
+)
+
+const (
+	defaultInputFile  = "example/input.conllu"
+	defaultOutputFile = "example/pairs.txt"
+	defaultToLower    = false
+)
+
+var (
+	inputFile  string
+	outputFile string
+	toLower    bool
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "conllu",
+		Short:   "Convert CoNLL-U dependency trees into word-context pairs for word2vecf",
+		Example: "  wego conllu -i example/input.conllu -o example/pairs.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmd.Flags().StringVarP(&inputFile, "input", "i", defaultInputFile, "input file path for CoNLL-U")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save word-context pairs")
+	cmd.Flags().BoolVar(&toLower, "to-lower", defaultToLower, "whether the words convert to lowercase or not")
+	return cmd
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func execute() error {
+	if fileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	} else if !fileExists(inputFile) {
+		return errors.Errorf("Not such a file %s", inputFile)
+	}
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0777); err != nil {
+		return err
+	}
+	input, err := os.Open(inputFile)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	output, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	writer := bufio.NewWriter(output)
+	if err := conllu.Contexts(input, toLower, func(word, context string) error {
+		_, err := fmt.Fprintf(writer, "%s %s\n", word, context)
+		return err
+	}); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
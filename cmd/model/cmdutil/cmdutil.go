@@ -15,24 +15,48 @@
 package cmdutil
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/pprof"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"github.com/ynqa/wego/pkg/config"
+	"github.com/ynqa/wego/pkg/metadata"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/loss"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 )
 
 const (
+	defaultFormat     = ""
 	defaultInputFile  = "example/input.txt"
+	defaultLossFile   = ""
 	defaultOutputFile = "example/word_vectors.txt"
 	defaultProf       = false
-	defaultVectorType = vector.Single
+	defaultVectorType = vector.Word
 )
 
+func AddFormatFlags(cmd *cobra.Command, format *vector.Format) {
+	cmd.Flags().StringVar(format, "format", defaultFormat, fmt.Sprintf("output format of word vectors. One of: %s|%s|%s (%s is %s with the header line; by the extension of the output if empty: %s for .bin, %s for .vec, %s otherwise)", vector.Text, vector.Vec, vector.Binary, vector.Vec, vector.Text, vector.Binary, vector.Vec, vector.Text))
+}
+
 func AddInputFlags(cmd *cobra.Command, input *string) {
 	cmd.Flags().StringVarP(input, "input", "i", defaultInputFile, "input file path for corpus")
 }
 
+func AddLossFileFlags(cmd *cobra.Command, lossFile *string) {
+	cmd.Flags().StringVar(lossFile, "loss-file", defaultLossFile, "output file path to save loss curve as csv (disabled if empty)")
+}
+
 func AddOutputFlags(cmd *cobra.Command, output *string) {
 	cmd.Flags().StringVarP(output, "output", "o", defaultOutputFile, "output file path to save word vectors")
 }
@@ -42,5 +66,265 @@ func AddProfFlags(cmd *cobra.Command, prof *bool) {
 }
 
 func AddVectorTypeFlags(cmd *cobra.Command, typ *vector.Type) {
-	cmd.Flags().StringVar(typ, "vec-type", defaultVectorType, fmt.Sprintf("word vector type. One of: %s|%s", vector.Single, vector.Agg))
+	cmd.Flags().StringVar(typ, "vec-type", defaultVectorType, fmt.Sprintf("word vector type. One of: %s|%s|%s|%s|%s (%s and %s are accepted as the former names of %s and %s)", vector.Word, vector.Context, vector.Sum, vector.Average, vector.Concat, vector.Single, vector.Agg, vector.Word, vector.Sum))
+}
+
+// Files are the flags to train a model other than its options.
+type Files struct {
+	Format     vector.Format
+	Input      string
+	LossFile   string
+	Output     string
+	Prof       bool
+	VectorType vector.Type
+}
+
+// NewCommand returns the command to train the model of reg, named by reg.Name.
+func NewCommand(reg model.Registration) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   reg.Name,
+		Short: reg.Short,
+	}
+	SetTrain(cmd, reg)
+	return cmd
+}
+
+// SetTrain adds the flags of Files and the options of the model of reg to cmd, and makes cmd
+// train the model. The options whose names are already used by the flags of cmd are prefixed
+// by the model name, e.g. --model of word2vec is --word2vec-model in `wego train --model`.
+func SetTrain(cmd *cobra.Command, reg model.Registration) {
+	var files Files
+	AddFormatFlags(cmd, &files.Format)
+	AddInputFlags(cmd, &files.Input)
+	AddLossFileFlags(cmd, &files.LossFile)
+	AddOutputFlags(cmd, &files.Output)
+	AddProfFlags(cmd, &files.Prof)
+	AddVectorTypeFlags(cmd, &files.VectorType)
+
+	factory := reg.Factory()
+	AddModelFlags(cmd, reg, factory)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		return Train(reg.Name, factory, files)
+	}
+}
+
+// AddModelFlags adds the options of the model of reg, which are bound to factory, to cmd.
+// The options whose names are already used by the flags of cmd are prefixed by the model name.
+// It returns the names of the options keyed by the names of their flags.
+func AddModelFlags(cmd *cobra.Command, reg model.Registration, factory model.Factory) map[string]string {
+	names := make(map[string]string)
+	opts := &cobra.Command{}
+	factory.LoadForCmd(opts)
+	opts.Flags().VisitAll(func(f *pflag.Flag) {
+		name := f.Name
+		if cmd.Flags().Lookup(f.Name) != nil {
+			f.Name = reg.Name + "-" + f.Name
+		}
+		if f.Shorthand != "" && cmd.Flags().ShorthandLookup(f.Shorthand) != nil {
+			f.Shorthand = ""
+		}
+		cmd.Flags().AddFlag(f)
+		names[f.Name] = name
+	})
+	return names
+}
+
+// Train runs the workflow to train the model named name created by factory: profiling,
+// handling the signals and Run.
+func Train(name string, factory model.Factory, files Files) error {
+	if files.Prof {
+		f, err := os.Create("cpu.prof")
+		if err != nil {
+			return err
+		}
+		pprof.StartCPUProfile(f)
+		defer pprof.StopCPUProfile()
+	}
+
+	ctx, stop := SignalContext()
+	defer stop()
+	_, err := Run(ctx, name, factory, files)
+	return err
+}
+
+// Run checks the files, trains the model named name created by factory until ctx is done, and
+// saves the vectors, the loss and the metadata of the run, which is returned.
+func Run(ctx context.Context, name string, factory model.Factory, files Files) (*metadata.Metadata, error) {
+	mod, err := factory.New()
+	if err != nil {
+		return nil, err
+	}
+	format := files.Format
+	if format == "" {
+		format = vector.FormatOf(files.Output)
+	}
+	if err := vector.ValidateFormat(format); err != nil {
+		return nil, err
+	}
+
+	if fileExists(files.Output) {
+		return nil, errors.Errorf("%s is already existed", files.Output)
+	} else if !fileExists(files.Input) {
+		return nil, errors.Errorf("Not such a file %s", files.Input)
+	}
+	if err := os.MkdirAll(filepath.Dir(files.Output), 0777); err != nil {
+		return nil, err
+	}
+	output, err := os.Create(files.Output)
+	if err != nil {
+		return nil, err
+	}
+	defer output.Close()
+	input, err := os.Open(files.Input)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	meta := metadata.New(name, factory.Options())
+	meta.Output = files.Output
+	meta.VectorType = files.VectorType
+	meta.Format = format
+	if err := meta.AddInput(files.Input); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	if err := mod.Train(ctx, input); err != nil {
+		if !Stopped(err) {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "warning: training is stopped before completion (%v), save the vectors trained so far\n", err)
+		meta.Stopped = true
+	}
+	meta.TrainSeconds = time.Since(start).Seconds()
+	if err := SaveLoss(files.LossFile, mod.Loss()); err != nil {
+		return nil, err
+	}
+	if err := model.SaveFormat(output, mod, files.VectorType, format); err != nil {
+		return nil, err
+	}
+	if r, ok := mod.(model.StatsReporter); ok {
+		meta.SetStats(r.Stats())
+	}
+	meta.SetLoss(mod.Loss())
+	if err := meta.Save(metadata.Path(files.Output)); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// SaveLoss writes the loss per epoch into path as csv. It does nothing if path is empty.
+func SaveLoss(path string, history []float64) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return loss.WriteCSV(f, history)
+}
+
+// SignalContext returns the context which is cancelled by SIGINT or SIGTERM.
+// After the first signal the default behavior is restored, so that the second one kills the process.
+func SignalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
+
+// Stopped reports whether training is stopped by a signal or the time budget.
+func Stopped(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// SelectModel returns the model selected by --model in args, or by the key model in the config
+// file of --config, for the commands which parse the flags by themselves. ok is false if no model
+// is selected.
+func SelectModel(args []string) (reg model.Registration, ok bool, err error) {
+	name, err := FlagValue(args, "model")
+	if err != nil {
+		return reg, false, err
+	}
+	if name == "" {
+		// the model can be also selected by the config file.
+		if name, err = ConfigModel(args); err != nil {
+			return reg, false, err
+		}
+	}
+	if name == "" {
+		return reg, false, nil
+	}
+	reg, ok = model.Lookup(name)
+	if !ok {
+		return reg, false, errors.Errorf("invalid model: %s not in %s", name, strings.Join(model.Names(), "|"))
+	}
+	return reg, true, nil
+}
+
+// DescribeModels returns the list of the registered models for the help of the commands.
+func DescribeModels() string {
+	var b strings.Builder
+	b.WriteString("Models:\n")
+	for _, name := range model.Names() {
+		reg, _ := model.Lookup(name)
+		fmt.Fprintf(&b, "  %-12s%s\n", name, reg.Short)
+	}
+	return b.String()
+}
+
+// ConfigModel returns the model in the config file given by --config in args, for the
+// commands which parse the flags by themselves.
+func ConfigModel(args []string) (string, error) {
+	path, err := FlagValue(args, config.ConfigFlag)
+	if err != nil || path == "" {
+		return "", err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return "", err
+	}
+	if v, ok := cfg["model"]; ok {
+		return fmt.Sprint(v), nil
+	}
+	return "", nil
+}
+
+// FlagValue returns the value of --<flag> in args, or empty if it's not given.
+func FlagValue(args []string, flag string) (string, error) {
+	var value string
+	for i := 0; i < len(args); i++ {
+		var v string
+		switch {
+		case args[i] == "--":
+			return value, nil
+		case args[i] == "--"+flag:
+			if i+1 >= len(args) {
+				return "", errors.Errorf("flag needs an argument: --%s", flag)
+			}
+			i++
+			v = args[i]
+		case strings.HasPrefix(args[i], "--"+flag+"="):
+			v = strings.TrimPrefix(args[i], "--"+flag+"=")
+		default:
+			continue
+		}
+		if value != "" {
+			return "", errors.Errorf("--%s is given more than once", flag)
+		}
+		value = v
+	}
+	return value, nil
 }
@@ -15,81 +15,12 @@
 package lexvec
 
 import (
-	"os"
-	"path/filepath"
-	"runtime/pprof"
-
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/ynqa/wego/cmd/model/cmdutil"
 	"github.com/ynqa/wego/pkg/model/lexvec"
-	"github.com/ynqa/wego/pkg/model/modelutil/vector"
-)
-
-var (
-	prof       bool
-	inputFile  string
-	outputFile string
-	vectorType vector.Type
 )
 
 func New() *cobra.Command {
-	var opts lexvec.Options
-	cmd := &cobra.Command{
-		Use:   "lexvec",
-		Short: "Lexvec: Matrix Factorization using Window Sampling and Negative Sampling for Improved Word Representations",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return execute(opts)
-		},
-	}
-
-	cmdutil.AddInputFlags(cmd, &inputFile)
-	cmdutil.AddOutputFlags(cmd, &outputFile)
-	cmdutil.AddProfFlags(cmd, &prof)
-	cmdutil.AddVectorTypeFlags(cmd, &vectorType)
-	lexvec.LoadForCmd(cmd, &opts)
-	return cmd
-}
-
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
-}
-
-func execute(opts lexvec.Options) error {
-	if prof {
-		f, err := os.Create("cpu.prof")
-		if err != nil {
-			return err
-		}
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
-	}
-
-	if fileExists(outputFile) {
-		return errors.Errorf("%s is already existed", outputFile)
-	} else if !fileExists(inputFile) {
-		return errors.Errorf("Not such a file %s", inputFile)
-	}
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0777); err != nil {
-		return err
-	}
-	output, err := os.Create(outputFile)
-	if err != nil {
-		return err
-	}
-	input, err := os.Open(inputFile)
-	if err != nil {
-		return err
-	}
-	defer input.Close()
-	mod, err := lexvec.NewForOptions(opts)
-	if err != nil {
-		return err
-	}
-	if err := mod.Train(input); err != nil {
-		return err
-	}
-	return mod.Save(output, vectorType)
+	return cmdutil.NewCommand(lexvec.Registration)
 }
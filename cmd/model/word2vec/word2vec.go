@@ -15,81 +15,12 @@
 package word2vec
 
 import (
-	"os"
-	"path/filepath"
-	"runtime/pprof"
-
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 
 	"github.com/ynqa/wego/cmd/model/cmdutil"
-	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 	"github.com/ynqa/wego/pkg/model/word2vec"
 )
 
-var (
-	prof       bool
-	inputFile  string
-	outputFile string
-	vectorType vector.Type
-)
-
 func New() *cobra.Command {
-	var opts word2vec.Options
-	cmd := &cobra.Command{
-		Use:   "word2vec",
-		Short: "Word2Vec: Continuous Bag-of-Words and Skip-gram model",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return execute(opts)
-		},
-	}
-
-	cmdutil.AddInputFlags(cmd, &inputFile)
-	cmdutil.AddOutputFlags(cmd, &outputFile)
-	cmdutil.AddProfFlags(cmd, &prof)
-	cmdutil.AddVectorTypeFlags(cmd, &vectorType)
-	word2vec.LoadForCmd(cmd, &opts)
-	return cmd
-}
-
-func fileExists(path string) bool {
-	_, err := os.Stat(path)
-	return err == nil
-}
-
-func execute(opts word2vec.Options) error {
-	if prof {
-		f, err := os.Create("cpu.prof")
-		if err != nil {
-			return err
-		}
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
-	}
-
-	if fileExists(outputFile) {
-		return errors.Errorf("%s is already existed", outputFile)
-	} else if !fileExists(inputFile) {
-		return errors.Errorf("%s is not found", inputFile)
-	}
-	if err := os.MkdirAll(filepath.Dir(outputFile), 0777); err != nil {
-		return err
-	}
-	output, err := os.Create(outputFile)
-	if err != nil {
-		return err
-	}
-	input, err := os.Open(inputFile)
-	if err != nil {
-		return err
-	}
-	defer input.Close()
-	mod, err := word2vec.NewForOptions(opts)
-	if err != nil {
-		return err
-	}
-	if err := mod.Train(input); err != nil {
-		return err
-	}
-	return mod.Save(output, vectorType)
+	return cmdutil.NewCommand(word2vec.Registration)
 }
@@ -0,0 +1,29 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vecf
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/model/word2vecf"
+)
+
+func New() *cobra.Command {
+	cmd := cmdutil.NewCommand(word2vecf.Registration)
+	cmd.Example = "  wego conllu -i example/input.conllu -o example/pairs.txt\n" +
+		"  wego word2vecf -i example/pairs.txt -o example/word_vectors.txt"
+	return cmd
+}
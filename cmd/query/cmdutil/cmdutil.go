@@ -15,7 +15,11 @@
 package cmdutil
 
 import (
+	"os"
+
 	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/metadata"
 )
 
 const (
@@ -24,9 +28,19 @@ const (
 )
 
 func AddInputFlags(cmd *cobra.Command, input *string) {
-	cmd.Flags().StringVarP(input, "input", "i", defaultInputFile, "input file path for trained word vector")
+	cmd.Flags().StringVarP(input, "input", "i", defaultInputFile, "input file path for trained word vector (in the binary format of word2vec for .bin)")
 }
 
 func AddRankFlags(cmd *cobra.Command, rank *int) {
 	cmd.Flags().IntVarP(rank, "rank", "r", defaultRank, "how many similar words will be displayed")
 }
+
+// DescribeMetadata shows the metadata of the run which produced the vectors in inputFile
+// on stderr. It does nothing if the vectors have no metadata.
+func DescribeMetadata(inputFile string) error {
+	meta, err := metadata.Load(inputFile)
+	if err != nil || meta == nil {
+		return err
+	}
+	return meta.Describe(os.Stderr)
+}
@@ -56,12 +56,10 @@ func execute() error {
 	if !fileExists(inputFile) {
 		return errors.Errorf("Not such a file %s", inputFile)
 	}
-	input, err := os.Open(inputFile)
-	if err != nil {
+	if err := cmdutil.DescribeMetadata(inputFile); err != nil {
 		return err
 	}
-	defer input.Close()
-	embs, err := embedding.Load(input)
+	embs, err := embedding.LoadFile(inputFile)
 	if err != nil {
 		return err
 	}
@@ -55,12 +55,10 @@ func execute(args []string) error {
 	} else if len(args) != 1 {
 		return errors.Errorf("Input a single word %v", args)
 	}
-	input, err := os.Open(inputFile)
-	if err != nil {
+	if err := cmdutil.DescribeMetadata(inputFile); err != nil {
 		return err
 	}
-	defer input.Close()
-	embs, err := embedding.Load(input)
+	embs, err := embedding.LoadFile(inputFile)
 	if err != nil {
 		return err
 	}
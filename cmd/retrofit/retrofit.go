@@ -0,0 +1,94 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrofit
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/retrofit"
+)
+
+const (
+	defaultInputFile   = "example/word_vectors.txt"
+	defaultLexiconFile = "example/lexicon.txt"
+	defaultOutputFile  = "example/retrofitted_vectors.txt"
+)
+
+var (
+	inputFile   string
+	lexiconFile string
+	outputFile  string
+)
+
+func New() *cobra.Command {
+	var opts retrofit.Options
+	cmd := &cobra.Command{
+		Use:     "retrofit",
+		Short:   "Retrofit word vectors to a semantic lexicon",
+		Example: "  wego retrofit -i example/word_vectors.txt -l example/lexicon.txt -o example/retrofitted_vectors.txt",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(opts)
+		},
+	}
+	cmd.Flags().StringVarP(&inputFile, "input", "i", defaultInputFile, "input file path for trained word vector (in the binary format of word2vec for .bin)")
+	cmd.Flags().StringVarP(&lexiconFile, "lexicon", "l", defaultLexiconFile, "input file path for lexicon, a word and its related words per line")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save retrofitted word vectors (in the binary format of word2vec for .bin, with the header line for .vec)")
+	retrofit.LoadForCmd(cmd, &opts)
+	return cmd
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func execute(opts retrofit.Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if fileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	}
+	for _, f := range []string{inputFile, lexiconFile} {
+		if !fileExists(f) {
+			return errors.Errorf("Not such a file %s", f)
+		}
+	}
+
+	embs, err := embedding.LoadFile(inputFile)
+	if err != nil {
+		return err
+	}
+	lexicon, err := os.Open(lexiconFile)
+	if err != nil {
+		return err
+	}
+	defer lexicon.Close()
+	lex, err := retrofit.LoadLexicon(lexicon)
+	if err != nil {
+		return err
+	}
+
+	res, err := retrofit.RetrofitForOptions(embs, lex, opts)
+	if err != nil {
+		return err
+	}
+
+	return embedding.SaveFile(outputFile, res)
+}
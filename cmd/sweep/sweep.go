@@ -0,0 +1,359 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/config"
+	"github.com/ynqa/wego/pkg/corpus"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/evaluate"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/sweep"
+)
+
+const (
+	defaultOutputDir   = "sweep"
+	defaultParallel    = 1
+	defaultResultsFile = ""
+	defaultSearchType  = sweep.Grid
+	defaultSearchSeed  = int64(1)
+	defaultTrials      = 10
+)
+
+type options struct {
+	files       cmdutil.Files
+	analogy     []string
+	outputDir   string
+	parallel    int
+	params      []string
+	resultsFile string
+	searchType  sweep.SearchType
+	searchSeed  int64
+	similarity  []string
+	trials      int
+}
+
+func New() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sweep",
+		Short: "Train the model for each configuration of the search space and evaluate the vectors",
+		Long:  long(),
+		Example: "  wego sweep --model word2vec -i example/input.txt -p dim=50,100 -p window=5,10 --similarity ws353.txt --analogy questions-words.txt\n" +
+			"  wego sweep --model glove -i example/input.txt --search random --trials 20 -p initlr=0.01..0.1:log -p dim=50..300 --parallel 2",
+		// the flags depend on the model, so that they're parsed in execute.
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(cmd, args)
+		},
+	}
+}
+
+func long() string {
+	var b strings.Builder
+	b.WriteString("Train the registered model selected by --model for each configuration of the search space,\n")
+	b.WriteString("and evaluate the vectors on the word similarity and analogy datasets.\n\n")
+	b.WriteString("The search space is given by --param <option>=<v1>,<v2>,... for the choices, or by\n")
+	b.WriteString("--param <option>=<min>..<max>[:log] for the range of random search. The other options of\n")
+	b.WriteString("the model are given as the flags of train. The vectors and the metadata of the runs are saved\n")
+	b.WriteString("into --output-dir, and the results are written into --results as tab separated values.\n")
+	b.WriteString("The runs with the same corpus settings share the loaded corpus.\n\n")
+	b.WriteString(cmdutil.DescribeModels())
+	return b.String()
+}
+
+func addFlags(cmd *cobra.Command, opts *options) {
+	cmdutil.AddFormatFlags(cmd, &opts.files.Format)
+	cmdutil.AddInputFlags(cmd, &opts.files.Input)
+	cmdutil.AddVectorTypeFlags(cmd, &opts.files.VectorType)
+	cmd.Flags().StringArrayVar(&opts.analogy, "analogy", nil, "analogy dataset to evaluate the vectors, a question a:b = c:d per line (repeatable)")
+	cmd.Flags().StringVarP(&opts.outputDir, "output-dir", "o", defaultOutputDir, "output directory to save the vectors and the metadata of the runs")
+	cmd.Flags().IntVar(&opts.parallel, "parallel", defaultParallel, "number of the runs trained concurrently (> 0)")
+	cmd.Flags().StringArrayVarP(&opts.params, "param", "p", nil, "search space of an option, e.g. dim=50,100 or initlr=0.001..0.1:log (repeatable)")
+	cmd.Flags().StringVar(&opts.resultsFile, "results", defaultResultsFile, "output file path to save the results as tsv (results.tsv in --output-dir if empty)")
+	cmd.Flags().StringVar(&opts.searchType, "search", defaultSearchType, fmt.Sprintf("search type. One of %s|%s", sweep.Grid, sweep.Random))
+	cmd.Flags().Int64Var(&opts.searchSeed, "search-seed", defaultSearchSeed, "random seed for random search")
+	cmd.Flags().StringArrayVar(&opts.similarity, "similarity", nil, "word similarity dataset to evaluate the vectors, a pair of words and the score per line (repeatable)")
+	cmd.Flags().IntVar(&opts.trials, "trials", defaultTrials, "number of the configurations for random search (> 0)")
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	reg, ok, err := cmdutil.SelectModel(args)
+	if err != nil {
+		return err
+	} else if !ok {
+		for _, arg := range args {
+			if arg == "-h" || arg == "--help" {
+				return cmd.Help()
+			}
+		}
+		return errors.Errorf("Set --model. One of %s", strings.Join(model.Names(), "|"))
+	}
+
+	// the usage of the model is shown by sub instead of cmd.
+	cmd.SilenceUsage = true
+	sub := &cobra.Command{
+		Use:           "sweep --model " + reg.Name,
+		Short:         reg.Short,
+		SilenceErrors: true,
+	}
+	var opts options
+	sub.Flags().String("model", reg.Name, fmt.Sprintf("model to sweep. One of %s", strings.Join(model.Names(), "|")))
+	addFlags(sub, &opts)
+	names := cmdutil.AddModelFlags(sub, reg, reg.Factory())
+	sub.RunE = func(sub *cobra.Command, args []string) error {
+		return run(reg, opts, baseOptions(sub.Flags(), names), names)
+	}
+	config.Bind(sub)
+	sub.SetArgs(args)
+	sub.SetOut(cmd.OutOrStdout())
+	sub.SetErr(cmd.ErrOrStderr())
+	return sub.Execute()
+}
+
+// baseOptions returns the values of the options given on the command line or the config file,
+// keyed by the names of the options.
+func baseOptions(fs *pflag.FlagSet, names map[string]string) map[string]string {
+	base := make(map[string]string)
+	fs.Visit(func(f *pflag.Flag) {
+		name, ok := names[f.Name]
+		if !ok {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			base[name] = strings.Join(sv.GetSlice(), ",")
+		} else {
+			base[name] = f.Value.String()
+		}
+	})
+	return base
+}
+
+// newFactory returns the factory whose options are set by base and then by c.
+func newFactory(reg model.Registration, base map[string]string, c sweep.Config, names map[string]string) (model.Factory, error) {
+	factory := reg.Factory()
+	opts := &cobra.Command{}
+	factory.LoadForCmd(opts)
+	for name, value := range base {
+		if err := opts.Flags().Set(name, value); err != nil {
+			return nil, errors.Wrapf(err, "invalid value for %s", name)
+		}
+	}
+	for _, s := range c {
+		if err := opts.Flags().Set(names[s.Name], s.Value); err != nil {
+			return nil, errors.Wrapf(err, "invalid value for %s", s.Name)
+		}
+	}
+	return factory, nil
+}
+
+type dataset struct {
+	name       string
+	similarity []evaluate.SimilarityPair
+	analogy    []evaluate.AnalogyQuestion
+}
+
+func loadDatasets(similarity, analogy []string) ([]dataset, error) {
+	var datasets []dataset
+	load := func(path string, fn func(*os.File, *dataset) error) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		d := dataset{name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+		if err := fn(f, &d); err != nil {
+			return errors.Wrapf(err, "failed to read %s", path)
+		}
+		datasets = append(datasets, d)
+		return nil
+	}
+	for _, path := range similarity {
+		if err := load(path, func(f *os.File, d *dataset) (err error) {
+			d.similarity, err = evaluate.ReadSimilarity(f)
+			return
+		}); err != nil {
+			return nil, err
+		}
+	}
+	for _, path := range analogy {
+		if err := load(path, func(f *os.File, d *dataset) (err error) {
+			d.analogy, err = evaluate.ReadAnalogy(f)
+			return
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return datasets, nil
+}
+
+func (d dataset) evaluate(idx *evaluate.Index) sweep.Score {
+	if d.similarity != nil {
+		return sweep.Score{Dataset: d.name, Result: idx.Similarity(d.similarity)}
+	}
+	return sweep.Score{Dataset: d.name, Result: idx.Analogy(d.analogy)}
+}
+
+func evaluateVectors(path string, datasets []dataset) ([]sweep.Score, error) {
+	if len(datasets) == 0 {
+		return nil, nil
+	}
+	embs, err := embedding.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	idx := evaluate.NewIndex(embs)
+	scores := make([]sweep.Score, len(datasets))
+	for i, d := range datasets {
+		scores[i] = d.evaluate(idx)
+	}
+	return scores, nil
+}
+
+func run(reg model.Registration, opts options, base map[string]string, names map[string]string) error {
+	if opts.parallel < 1 {
+		return errors.Errorf("parallel must be positive: %d", opts.parallel)
+	} else if opts.searchType == sweep.Random && opts.trials < 1 {
+		return errors.Errorf("trials must be positive: %d", opts.trials)
+	}
+	params, err := sweep.ParseParams(opts.params)
+	if err != nil {
+		return err
+	}
+	for _, p := range params {
+		if _, ok := names[p.Name]; !ok {
+			return errors.Errorf("unknown option of %s: %s", reg.Name, p.Name)
+		}
+	}
+	configs, err := sweep.Configs(opts.searchType, params, opts.trials, opts.searchSeed)
+	if err != nil {
+		return err
+	}
+	datasets, err := loadDatasets(opts.similarity, opts.analogy)
+	if err != nil {
+		return err
+	}
+	if opts.resultsFile == "" {
+		opts.resultsFile = filepath.Join(opts.outputDir, "results.tsv")
+	}
+	if _, err := os.Stat(opts.resultsFile); err == nil {
+		return errors.Errorf("%s is already existed", opts.resultsFile)
+	}
+
+	ctx, stop := cmdutil.SignalContext()
+	defer stop()
+	ctx = corpus.WithCache(ctx, corpus.NewCache())
+
+	results := make([]sweep.Result, len(configs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < opts.parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = runConfig(ctx, reg, opts, base, names, i+1, configs[i], datasets)
+			}
+		}()
+	}
+	for i := range configs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	header, rows := sweep.Table(results)
+	writer := tablewriter.NewWriter(os.Stdout)
+	writer.SetHeader(header)
+	writer.SetBorder(false)
+	writer.SetAutoFormatHeaders(false)
+	writer.AppendBulk(rows)
+	writer.Render()
+	return saveResults(opts.resultsFile, results)
+}
+
+func runConfig(
+	ctx context.Context,
+	reg model.Registration,
+	opts options,
+	base map[string]string,
+	names map[string]string,
+	run int,
+	c sweep.Config,
+	datasets []dataset,
+) sweep.Result {
+	res := sweep.Result{
+		Run:    run,
+		Config: c,
+		Output: filepath.Join(opts.outputDir, fmt.Sprintf("run-%03d.%s", run, ext(opts.files.Format))),
+	}
+	if ctx.Err() != nil {
+		res.Err = errors.New("skipped after the sweep is stopped")
+		return res
+	}
+	factory, err := newFactory(reg, base, c, names)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	files := opts.files
+	files.Output = res.Output
+	meta, err := cmdutil.Run(ctx, reg.Name, factory, files)
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	res.FinalLoss, res.TrainSeconds, res.Stopped = meta.FinalLoss, meta.TrainSeconds, meta.Stopped
+	res.Scores, res.Err = evaluateVectors(res.Output, datasets)
+	fmt.Fprintf(os.Stderr, "finished run %d: %s\n", run, res.Output)
+	return res
+}
+
+// ext returns the extension of the vectors in format.
+func ext(format vector.Format) string {
+	switch format {
+	case vector.Binary:
+		return "bin"
+	case vector.Vec:
+		return "vec"
+	default:
+		return "txt"
+	}
+}
+
+func saveResults(path string, results []sweep.Result) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sweep.WriteTSV(f, results)
+}
@@ -0,0 +1,82 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package train
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/cmd/model/cmdutil"
+	"github.com/ynqa/wego/pkg/config"
+	"github.com/ynqa/wego/pkg/model"
+)
+
+func New() *cobra.Command {
+	return &cobra.Command{
+		Use:   "train",
+		Short: "Train the registered model selected by --model",
+		Long:  long(),
+		Example: "  wego train --model glove -i example/input.txt -o example/word_vectors.txt --iter 10\n" +
+			"  wego train --model word2vec --word2vec-model skipgram --help",
+		// the flags depend on the model, so that they're parsed in execute.
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute(cmd, args)
+		},
+	}
+}
+
+func long() string {
+	var b strings.Builder
+	b.WriteString("Train the registered model selected by --model.\n\n")
+	b.WriteString("The flags are the options of the model, which are shown by --model <name> --help.\n")
+	b.WriteString("The options with the same names as the flags of train are prefixed by the model name.\n")
+	b.WriteString("The model can be also selected by the key model in the config file of --config.\n\n")
+	b.WriteString(cmdutil.DescribeModels())
+	return b.String()
+}
+
+func execute(cmd *cobra.Command, args []string) error {
+	reg, ok, err := cmdutil.SelectModel(args)
+	if err != nil {
+		return err
+	} else if !ok {
+		for _, arg := range args {
+			if arg == "-h" || arg == "--help" {
+				return cmd.Help()
+			}
+		}
+		return errors.Errorf("Set --model. One of %s", strings.Join(model.Names(), "|"))
+	}
+	name := reg.Name
+
+	// the usage of the model is shown by sub instead of cmd.
+	cmd.SilenceUsage = true
+	sub := &cobra.Command{
+		Use:           "train --model " + name,
+		Short:         reg.Short,
+		SilenceErrors: true,
+	}
+	sub.Flags().String("model", name, fmt.Sprintf("model to train. One of %s", strings.Join(model.Names(), "|")))
+	cmdutil.SetTrain(sub, reg)
+	config.Bind(sub)
+	sub.SetArgs(args)
+	sub.SetOut(cmd.OutOrStdout())
+	sub.SetErr(cmd.ErrOrStderr())
+	return sub.Execute()
+}
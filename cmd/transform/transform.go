@@ -0,0 +1,83 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/transform"
+)
+
+const (
+	defaultInputFile  = "example/word_vectors.txt"
+	defaultOutputFile = "example/transformed_vectors.txt"
+	defaultSteps      = "center,normalize"
+)
+
+var (
+	inputFile  string
+	outputFile string
+	steps      string
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "transform",
+		Short:   "Transform word vectors by centering, normalization, all-but-the-top and PCA",
+		Example: "  wego transform -i example/word_vectors.txt -o example/transformed_vectors.txt --steps center,abtt=2,pca=100,normalize",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return execute()
+		},
+	}
+	cmd.Flags().StringVarP(&inputFile, "input", "i", defaultInputFile, "input file path for trained word vector (in the binary format of word2vec for .bin)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", defaultOutputFile, "output file path to save transformed word vectors (in the binary format of word2vec for .bin, with the header line for .vec)")
+	cmd.Flags().StringVar(&steps, "steps", defaultSteps, fmt.Sprintf("comma separated steps to apply in order. Each of: %s|%s|%s=<components to remove>|%s=<dimension>",
+		transform.CenterStep, transform.NormalizeStep, transform.RemoveTopStep, transform.PCAStep))
+	return cmd
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func execute() error {
+	parsed, err := transform.ParseSteps(steps)
+	if err != nil {
+		return err
+	}
+	if fileExists(outputFile) {
+		return errors.Errorf("%s is already existed", outputFile)
+	} else if !fileExists(inputFile) {
+		return errors.Errorf("Not such a file %s", inputFile)
+	}
+
+	embs, err := embedding.LoadFile(inputFile)
+	if err != nil {
+		return err
+	}
+
+	res, err := transform.Apply(embs, parsed...)
+	if err != nil {
+		return err
+	}
+
+	return embedding.SaveFile(outputFile, res)
+}
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
@@ -35,10 +36,10 @@ func main() {
 
 	input, _ := os.Open("text8")
 	defer input.Close()
-	if err = model.Train(input); err != nil {
+	if err = model.Train(context.Background(), input); err != nil {
 		// failed to train.
 	}
 
 	// write word vector.
-	model.Save(os.Stdin, vector.Agg)
+	model.Save(os.Stdin, vector.Sum)
 }
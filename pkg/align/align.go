@@ -0,0 +1,330 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package align maps an embedding space onto another one by the orthogonal Procrustes solution.
+package align
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/linalg"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+// Pair is the source word and its translation in the target space.
+type Pair struct {
+	Source string
+	Target string
+}
+
+type Dictionary []Pair
+
+// LoadDictionary reads the dictionary, one pair per line separated by spaces:
+//
+//	<source> <target>
+//
+// The source word can have several lines for its translations.
+func LoadDictionary(r io.Reader) (Dictionary, error) {
+	var dic Dictionary
+	s := bufio.NewScanner(r)
+	var line int
+	for s.Scan() {
+		line++
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, errors.Errorf("line %d: expected <source> <target> but got %d fields", line, len(fields))
+		}
+		dic = append(dic, Pair{Source: fields[0], Target: fields[1]})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return dic, nil
+}
+
+// IdenticalDictionary returns the pairs of the words which are shared by src and tgt.
+func IdenticalDictionary(src, tgt embedding.Embeddings) Dictionary {
+	index := indexOf(tgt)
+	var dic Dictionary
+	for _, emb := range src {
+		if _, ok := index[emb.Word]; ok {
+			dic = append(dic, Pair{Source: emb.Word, Target: emb.Word})
+		}
+	}
+	return dic
+}
+
+// Mapping is the orthogonal matrix R = U Vᵀ, which maps the source vector x to R x.
+type Mapping struct {
+	// u and v store the columns of U and V as the rows.
+	u [][]float64
+	v [][]float64
+}
+
+// Map returns the mapped vector of vec.
+func (m *Mapping) Map(vec []float64) []float64 {
+	res := make([]float64, len(vec))
+	for k := range m.u {
+		vecmath.Axpy(vecmath.Dot(m.v[k], vec), m.u[k], res)
+	}
+	return res
+}
+
+// Apply returns the mapped embeddings of embs.
+func (m *Mapping) Apply(embs embedding.Embeddings) embedding.Embeddings {
+	res := make(embedding.Embeddings, len(embs))
+	for i, emb := range embs {
+		vec := m.Map(emb.Vector)
+		res[i] = embedding.Embedding{
+			Word:   emb.Word,
+			Dim:    emb.Dim,
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+	}
+	return res
+}
+
+// Procrustes solves the orthogonal R which minimizes sum ||R x - y||^2 over the pairs (x, y) of dic:
+// R = U Vᵀ for the singular value decomposition U S Vᵀ of sum y xᵀ.
+// The pairs which are not found in src or tgt are skipped.
+func Procrustes(src, tgt embedding.Embeddings, dic Dictionary) (*Mapping, error) {
+	if err := checkEmbeddings(src, tgt); err != nil {
+		return nil, err
+	}
+	srcIndex, tgtIndex := indexOf(src), indexOf(tgt)
+	dim := src[0].Dim
+	m := make([][]float64, dim)
+	for i := range m {
+		m[i] = make([]float64, dim)
+	}
+	var n int
+	for _, p := range dic {
+		i, ok1 := srcIndex[p.Source]
+		j, ok2 := tgtIndex[p.Target]
+		if !ok1 || !ok2 {
+			continue
+		}
+		x, y := src[i].Vector, tgt[j].Vector
+		for k := 0; k < dim; k++ {
+			vecmath.Axpy(y[k], x, m[k])
+		}
+		n++
+	}
+	if n == 0 {
+		return nil, errors.New("no pairs of dictionary are found in both embeddings")
+	}
+
+	_, u, v := linalg.SVD(m)
+	complete(v)
+	return &Mapping{u: u, v: v}, nil
+}
+
+// complete replaces the zero rows of the orthonormal rows a, which are the singular vectors for
+// the zero singular values, with the unit vectors orthogonal to the others.
+func complete(a [][]float64) {
+	var e int
+	for i := range a {
+		for vecmath.Norm(a[i]) == 0 && e < len(a[i]) {
+			a[i][e] = 1
+			e++
+			for j := range a {
+				if j != i {
+					vecmath.Axpy(-vecmath.Dot(a[j], a[i]), a[j], a[i])
+				}
+			}
+			if norm := vecmath.Norm(a[i]); norm > 1e-6 {
+				vecmath.Scale(1/norm, a[i])
+			} else {
+				vecmath.Zero(a[i])
+			}
+		}
+	}
+}
+
+// Align solves the mapping from dic, and refines it Refine times by Procrustes on the dictionary
+// which consists of the mutual nearest neighbors among the leading RefineVocab words of the mapped
+// src and tgt. If dic is empty, the identical words are used as the seed dictionary.
+func Align(src, tgt embedding.Embeddings, dic Dictionary, opts ...Option) (*Mapping, error) {
+	options := DefaultOptions()
+	for _, fn := range opts {
+		fn(&options)
+	}
+	return AlignForOptions(src, tgt, dic, options)
+}
+
+func AlignForOptions(src, tgt embedding.Embeddings, dic Dictionary, opts Options) (*Mapping, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	verbose := verbose.New(opts.Verbose)
+	if len(dic) == 0 {
+		dic = IdenticalDictionary(src, tgt)
+		verbose.Do(func() {
+			fmt.Printf("use %d identical words as seed dictionary\n", len(dic))
+		})
+	}
+	mapping, err := Procrustes(src, tgt, dic)
+	if err != nil {
+		return nil, err
+	}
+	for i := 1; i <= opts.Refine; i++ {
+		dic = mutualNeighbors(mapping.Apply(head(src, opts.RefineVocab)), head(tgt, opts.RefineVocab))
+		verbose.Do(func() {
+			fmt.Printf("refine %d with %d mutual nearest neighbors\n", i, len(dic))
+		})
+		if len(dic) == 0 {
+			break
+		}
+		if mapping, err = Procrustes(src, tgt, dic); err != nil {
+			return nil, err
+		}
+	}
+	return mapping, nil
+}
+
+// Precision returns precision@k of the translations of dic for the mapped source embeddings,
+// which is the ratio of the source words whose k nearest target words by cosine similarity
+// contain any of its translations, and the number of the source words to be evaluated.
+// The pairs which are not found in mapped or tgt are skipped.
+func Precision(mapped, tgt embedding.Embeddings, dic Dictionary, k int) (float64, int, error) {
+	if err := checkEmbeddings(mapped, tgt); err != nil {
+		return 0, 0, err
+	}
+	if k <= 0 {
+		return 0, 0, errors.Errorf("k must be positive but got %d", k)
+	}
+	srcIndex, tgtIndex := indexOf(mapped), indexOf(tgt)
+	gold := make(map[string]map[int]bool)
+	var sources []string
+	for _, p := range dic {
+		_, ok1 := srcIndex[p.Source]
+		j, ok2 := tgtIndex[p.Target]
+		if !ok1 || !ok2 {
+			continue
+		}
+		if gold[p.Source] == nil {
+			gold[p.Source] = make(map[int]bool)
+			sources = append(sources, p.Source)
+		}
+		gold[p.Source][j] = true
+	}
+	if len(sources) == 0 {
+		return 0, 0, errors.New("no pairs of dictionary are found in both embeddings")
+	}
+
+	var hit int
+	for _, word := range sources {
+		for _, j := range nearest(mapped[srcIndex[word]], tgt, k) {
+			if gold[word][j] {
+				hit++
+				break
+			}
+		}
+	}
+	return float64(hit) / float64(len(sources)), len(sources), nil
+}
+
+func checkEmbeddings(src, tgt embedding.Embeddings) error {
+	if src.Empty() || tgt.Empty() {
+		return errors.New("no embeddings to align")
+	}
+	if err := src.Validate(); err != nil {
+		return err
+	}
+	if err := tgt.Validate(); err != nil {
+		return err
+	}
+	if src[0].Dim != tgt[0].Dim {
+		return errors.Errorf("dimension of source and target must be the same: %d and %d", src[0].Dim, tgt[0].Dim)
+	}
+	return nil
+}
+
+func indexOf(embs embedding.Embeddings) map[string]int {
+	index := make(map[string]int, len(embs))
+	for i, emb := range embs {
+		if _, ok := index[emb.Word]; !ok {
+			index[emb.Word] = i
+		}
+	}
+	return index
+}
+
+func head(embs embedding.Embeddings, n int) embedding.Embeddings {
+	if len(embs) < n {
+		return embs
+	}
+	return embs[:n]
+}
+
+func cosine(a, b embedding.Embedding) float64 {
+	if a.Norm == 0 || b.Norm == 0 {
+		return 0
+	}
+	return vecmath.Dot(a.Vector, b.Vector) / (a.Norm * b.Norm)
+}
+
+// nearest returns the indices of the k nearest embs to q by cosine similarity in descending order.
+func nearest(q embedding.Embedding, embs embedding.Embeddings, k int) []int {
+	if k > len(embs) {
+		k = len(embs)
+	}
+	ids, sims := make([]int, 0, k+1), make([]float64, 0, k+1)
+	for i, emb := range embs {
+		sim := cosine(q, emb)
+		if len(ids) == k && sim <= sims[k-1] {
+			continue
+		}
+		pos := len(ids)
+		for pos > 0 && sims[pos-1] < sim {
+			pos--
+		}
+		ids = append(ids[:pos], append([]int{i}, ids[pos:]...)...)
+		sims = append(sims[:pos], append([]float64{sim}, sims[pos:]...)...)
+		if len(ids) > k {
+			ids, sims = ids[:k], sims[:k]
+		}
+	}
+	return ids
+}
+
+func mutualNeighbors(src, tgt embedding.Embeddings) Dictionary {
+	forward := make([]int, len(src))
+	for i, emb := range src {
+		forward[i] = nearest(emb, tgt, 1)[0]
+	}
+	backward := make([]int, len(tgt))
+	for j, emb := range tgt {
+		backward[j] = nearest(emb, src, 1)[0]
+	}
+	var dic Dictionary
+	for i, j := range forward {
+		if backward[j] == i {
+			dic = append(dic, Pair{Source: src[i].Word, Target: tgt[j].Word})
+		}
+	}
+	return dic
+}
@@ -0,0 +1,118 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package align
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/linalg"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+// testSpaces returns the target embeddings and the source embeddings which are rotated from them
+// with small noise. The source word of "t<i>" is "s<i>".
+func testSpaces(n, dim int) (embedding.Embeddings, embedding.Embeddings, Dictionary) {
+	rnd := rand.New(rand.NewSource(1))
+	rot := make([][]float64, dim)
+	for i := range rot {
+		rot[i] = make([]float64, dim)
+		for j := range rot[i] {
+			rot[i][j] = rnd.NormFloat64()
+		}
+	}
+	linalg.Orthonormalize(rot)
+
+	src, tgt := make(embedding.Embeddings, n), make(embedding.Embeddings, n)
+	dic := make(Dictionary, n)
+	for i := 0; i < n; i++ {
+		y, x := make([]float64, dim), make([]float64, dim)
+		for j := range y {
+			y[j] = rnd.NormFloat64()
+		}
+		for j := range x {
+			x[j] = vecmath.Dot(rot[j], y) + rnd.NormFloat64()*0.01
+		}
+		tgt[i] = embedding.Embedding{Word: fmt.Sprintf("t%d", i), Dim: dim, Vector: y, Norm: embutil.Norm(y)}
+		src[i] = embedding.Embedding{Word: fmt.Sprintf("s%d", i), Dim: dim, Vector: x, Norm: embutil.Norm(x)}
+		dic[i] = Pair{Source: src[i].Word, Target: tgt[i].Word}
+	}
+	return src, tgt, dic
+}
+
+func TestAlign(t *testing.T) {
+	src, tgt, dic := testSpaces(300, 8)
+
+	mapping, err := Align(src, tgt, dic[:50], Refine(0))
+	assert.NoError(t, err)
+	p, n, err := Precision(mapping.Apply(src), tgt, dic[50:], 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 250, n)
+	assert.Equal(t, 1.0, p)
+
+	// the refinement recovers the mapping from the seed dictionary with the wrong pairs.
+	seed := append(Dictionary{}, dic[:20]...)
+	for i := 0; i < 5; i++ {
+		seed = append(seed, Pair{Source: dic[i].Source, Target: dic[i+100].Target})
+	}
+	mapping, err = Align(src, tgt, seed, Refine(0))
+	assert.NoError(t, err)
+	p, _, err = Precision(mapping.Apply(src), tgt, dic, 1)
+	assert.NoError(t, err)
+	assert.Less(t, p, 1.0)
+
+	mapping, err = Align(src, tgt, seed, Refine(3))
+	assert.NoError(t, err)
+	p, _, err = Precision(mapping.Apply(src), tgt, dic, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, p)
+}
+
+func TestAlignIdentical(t *testing.T) {
+	src, tgt, _ := testSpaces(100, 4)
+	for i := range src {
+		src[i].Word = tgt[i].Word
+	}
+	mapping, err := Align(src, tgt, nil, Refine(0))
+	assert.NoError(t, err)
+	p, _, err := Precision(mapping.Apply(src), tgt, IdenticalDictionary(src, tgt), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, p)
+}
+
+func TestProcrustesRankDeficient(t *testing.T) {
+	src, tgt, dic := testSpaces(10, 4)
+	mapping, err := Procrustes(src, tgt, dic[:2])
+	assert.NoError(t, err)
+	// the mapping is still orthogonal, so that it keeps the norms.
+	for _, emb := range src {
+		assert.InDelta(t, emb.Norm, vecmath.Norm(mapping.Map(emb.Vector)), 1e-6)
+	}
+}
+
+func TestLoadDictionary(t *testing.T) {
+	dic, err := LoadDictionary(strings.NewReader("a b\n\nc d\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, Dictionary{{"a", "b"}, {"c", "d"}}, dic)
+
+	_, err = LoadDictionary(strings.NewReader("a b c\n"))
+	assert.EqualError(t, err, "line 1: expected <source> <target> but got 3 fields")
+}
@@ -0,0 +1,75 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package align
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/validate"
+)
+
+var (
+	defaultRefine      = 5
+	defaultRefineVocab = 5000
+	defaultVerbose     = false
+)
+
+type Options struct {
+	Refine      int
+	RefineVocab int
+	Verbose     bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Refine:      defaultRefine,
+		RefineVocab: defaultRefineVocab,
+		Verbose:     defaultVerbose,
+	}
+}
+
+// Validate reports all invalid values of Options at once.
+func (opts Options) Validate() error {
+	v := validate.New()
+	v.NonNegative("Refine", opts.Refine)
+	v.Positive("RefineVocab", opts.RefineVocab)
+	return v.Err()
+}
+
+func LoadForCmd(cmd *cobra.Command, opts *Options) {
+	cmd.Flags().IntVar(&opts.Refine, "refine", defaultRefine, "number of refinement iterations on the dictionary induced by mutual nearest neighbors")
+	cmd.Flags().IntVar(&opts.RefineVocab, "refine-vocab", defaultRefineVocab, "number of the leading words in each file to induce the dictionary for refinement")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
+}
+
+type Option func(*Options)
+
+func Refine(v int) Option {
+	return Option(func(opts *Options) {
+		opts.Refine = v
+	})
+}
+
+func RefineVocab(v int) Option {
+	return Option(func(opts *Options) {
+		opts.RefineVocab = v
+	})
+}
+
+func Verbose() Option {
+	return Option(func(opts *Options) {
+		opts.Verbose = true
+	})
+}
@@ -0,0 +1,255 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package config loads the run configurations of the commands from YAML, JSON or TOML
+// files. The keys of a configuration are the names of the flags, e.g.
+//
+//	input: corpus.txt
+//	dim: 100
+//	lr-schedule: cosine
+//
+// and the flags given on the command line override the values in the file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+type Format = string
+
+const (
+	YAML Format = "yaml"
+	JSON Format = "json"
+	TOML Format = "toml"
+)
+
+const (
+	// ConfigFlag and DumpConfigFlag are the names of the flags added by Bind.
+	ConfigFlag     = "config"
+	DumpConfigFlag = "dump-config"
+)
+
+func invalidFormatError(format Format) error {
+	return errors.Errorf("invalid config format: %s not in %s|%s|%s", format, YAML, JSON, TOML)
+}
+
+// FormatOf returns the format of path by its extension.
+func FormatOf(path string) (Format, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		return YAML, nil
+	case ".json":
+		return JSON, nil
+	case ".toml":
+		return TOML, nil
+	default:
+		return "", errors.Errorf("unknown extension of config file: %s (one of .yaml|.yml|.json|.toml)", path)
+	}
+}
+
+// Load reads the configuration from path in the format by its extension.
+func Load(path string) (map[string]interface{}, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg, err := Read(f, format)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", path)
+	}
+	return cfg, nil
+}
+
+// Read reads the configuration in format from r.
+func Read(r io.Reader, format Format) (map[string]interface{}, error) {
+	cfg := make(map[string]interface{})
+	switch format {
+	case YAML:
+		if err := yaml.NewDecoder(r).Decode(&cfg); err != nil && err != io.EOF {
+			return nil, err
+		}
+	case JSON:
+		dec := json.NewDecoder(r)
+		// keep the numbers as they are written, so that the integers are not formatted as floats.
+		dec.UseNumber()
+		if err := dec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+	case TOML:
+		if _, err := toml.NewDecoder(r).Decode(&cfg); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, invalidFormatError(format)
+	}
+	return cfg, nil
+}
+
+// Apply sets the flags in fs by cfg, except the ones changed on the command line.
+// The keys may use underscores instead of hyphens. It fails on the keys which are not
+// the flags, so that the typos are not ignored.
+func Apply(fs *pflag.FlagSet, cfg map[string]interface{}) error {
+	keys := make([]string, 0, len(cfg))
+	for key := range cfg {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := strings.ReplaceAll(key, "_", "-")
+		f := fs.Lookup(name)
+		if f == nil || name == ConfigFlag || name == DumpConfigFlag {
+			return errors.Errorf("unknown key in config: %s", key)
+		}
+		if f.Changed {
+			continue
+		}
+		v, err := format(cfg[key])
+		if err != nil {
+			return errors.Wrapf(err, "invalid value for %s", key)
+		}
+		if err := fs.Set(name, v); err != nil {
+			return errors.Wrapf(err, "invalid value for %s", key)
+		}
+	}
+	return nil
+}
+
+// format returns the flag value for v.
+func format(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := format(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", errors.New("must be a scalar or a list")
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case nil:
+		return "", nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// Dump writes the values of the flags in fs into w in format, except the ones by Bind
+// and help.
+func Dump(w io.Writer, fs *pflag.FlagSet, format Format) error {
+	cfg := make(map[string]interface{})
+	fs.VisitAll(func(f *pflag.Flag) {
+		switch f.Name {
+		case ConfigFlag, DumpConfigFlag, "help":
+			return
+		}
+		cfg[f.Name] = value(f)
+	})
+
+	switch format {
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(cfg); err != nil {
+			return err
+		}
+		return enc.Close()
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cfg)
+	case TOML:
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+			return err
+		}
+		_, err := w.Write(buf.Bytes())
+		return err
+	default:
+		return invalidFormatError(format)
+	}
+}
+
+// value returns the value of f in its type.
+func value(f *pflag.Flag) interface{} {
+	s := f.Value.String()
+	switch f.Value.Type() {
+	case "bool":
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v
+		}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return v
+		}
+	case "float32", "float64":
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	}
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		return sv.GetSlice()
+	}
+	return s
+}
+
+// Bind adds --config and --dump-config to cmd. Before running cmd, the flags are set from
+// the config file unless they're given on the command line, and --dump-config prints the
+// effective configuration instead of running cmd.
+func Bind(cmd *cobra.Command) {
+	var path, dump string
+	cmd.Flags().StringVar(&path, ConfigFlag, "", "config file of the flags (one of .yaml|.yml|.json|.toml), which are overridden by the command line")
+	cmd.Flags().StringVar(&dump, DumpConfigFlag, "", fmt.Sprintf("print the effective config in the format instead of running. One of %s|%s|%s", YAML, JSON, TOML))
+	cmd.Flags().Lookup(DumpConfigFlag).NoOptDefVal = YAML
+
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if path != "" {
+			cfg, err := Load(path)
+			if err != nil {
+				return err
+			}
+			if err := Apply(cmd.Flags(), cfg); err != nil {
+				return err
+			}
+		}
+		if dump != "" {
+			return Dump(cmd.OutOrStdout(), cmd.Flags(), dump)
+		}
+		return run(cmd, args)
+	}
+}
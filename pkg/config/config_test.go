@@ -0,0 +1,147 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("input", "in.txt", "")
+	fs.Int("dim", 10, "")
+	fs.Float64("initlr", 0.025, "")
+	fs.Bool("verbose", false, "")
+	fs.Duration("time-budget", 0, "")
+	fs.StringSlice("steps", []string{"center"}, "")
+	return fs
+}
+
+func TestRead(t *testing.T) {
+	testCases := []struct {
+		format Format
+		config string
+	}{
+		{format: YAML, config: "input: corpus.txt\ndim: 100\ninitlr: 0.05\nverbose: true\ntime_budget: 30m\nsteps: [center, normalize]\n"},
+		{format: JSON, config: `{"input": "corpus.txt", "dim": 100, "initlr": 0.05, "verbose": true, "time-budget": "30m", "steps": ["center", "normalize"]}`},
+		{format: TOML, config: "input = \"corpus.txt\"\ndim = 100\ninitlr = 0.05\nverbose = true\ntime-budget = \"30m\"\nsteps = [\"center\", \"normalize\"]\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			cfg, err := Read(strings.NewReader(tc.config), tc.format)
+			assert.NoError(t, err)
+			fs := newFlagSet()
+			assert.NoError(t, Apply(fs, cfg))
+
+			input, _ := fs.GetString("input")
+			dim, _ := fs.GetInt("dim")
+			initlr, _ := fs.GetFloat64("initlr")
+			verbose, _ := fs.GetBool("verbose")
+			budget, _ := fs.GetDuration("time-budget")
+			steps, _ := fs.GetStringSlice("steps")
+			assert.Equal(t, "corpus.txt", input)
+			assert.Equal(t, 100, dim)
+			assert.Equal(t, 0.05, initlr)
+			assert.True(t, verbose)
+			assert.Equal(t, 30*time.Minute, budget)
+			assert.Equal(t, []string{"center", "normalize"}, steps)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	fs := newFlagSet()
+	assert.NoError(t, fs.Parse([]string{"--dim", "50"}))
+	// the flags on the command line override the config.
+	assert.NoError(t, Apply(fs, map[string]interface{}{"dim": 100, "input": "corpus.txt"}))
+	dim, _ := fs.GetInt("dim")
+	input, _ := fs.GetString("input")
+	assert.Equal(t, 50, dim)
+	assert.Equal(t, "corpus.txt", input)
+
+	assert.Error(t, Apply(newFlagSet(), map[string]interface{}{"dimm": 100}))
+	assert.Error(t, Apply(newFlagSet(), map[string]interface{}{"dim": "ten"}))
+	assert.Error(t, Apply(newFlagSet(), map[string]interface{}{"dim": map[string]interface{}{"a": 1}}))
+}
+
+func TestDump(t *testing.T) {
+	for _, format := range []Format{YAML, JSON, TOML} {
+		t.Run(format, func(t *testing.T) {
+			fs := newFlagSet()
+			assert.NoError(t, fs.Parse([]string{"--dim", "50", "--initlr", "0.1", "--steps", "pca=2,normalize"}))
+			var buf bytes.Buffer
+			assert.NoError(t, Dump(&buf, fs, format))
+
+			cfg, err := Read(&buf, format)
+			assert.NoError(t, err)
+			restored := newFlagSet()
+			assert.NoError(t, Apply(restored, cfg))
+			fs.VisitAll(func(f *pflag.Flag) {
+				assert.Equal(t, f.Value.String(), restored.Lookup(f.Name).Value.String(), f.Name)
+			})
+		})
+	}
+	assert.Error(t, Dump(&bytes.Buffer{}, newFlagSet(), "xml"))
+}
+
+func TestBind(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("dim: 100\ninput: corpus.txt\n"), 0644))
+
+	var (
+		dim   int
+		input string
+		ran   bool
+	)
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{
+			Use: "test",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				ran = true
+				return nil
+			},
+		}
+		cmd.Flags().IntVar(&dim, "dim", 10, "")
+		cmd.Flags().StringVar(&input, "input", "in.txt", "")
+		Bind(cmd)
+		return cmd
+	}
+
+	cmd := newCmd()
+	cmd.SetArgs([]string{"--config", path, "--dim", "50"})
+	assert.NoError(t, cmd.Execute())
+	assert.True(t, ran)
+	assert.Equal(t, 50, dim)
+	assert.Equal(t, "corpus.txt", input)
+
+	ran = false
+	var buf bytes.Buffer
+	cmd = newCmd()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--config", path, "--dump-config"})
+	assert.NoError(t, cmd.Execute())
+	assert.False(t, ran)
+	assert.Equal(t, "dim: 100\ninput: corpus.txt\n", buf.String())
+}
@@ -0,0 +1,126 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+// Sharer is implemented by the corpora which can be shared after Load. Share returns the
+// corpus with the same dictionary and co-occurrences, which reads the document from doc.
+type Sharer interface {
+	Share(doc io.ReadSeeker) Corpus
+}
+
+// Key identifies the loaded corpus in Cache by the settings which change its contents.
+type Key struct {
+	InMemory bool
+	ToLower  bool
+	MaxCount int
+	MinCount int
+	With     WithCooccurrence
+	// Cooccurrence is whether With is used.
+	Cooccurrence bool
+}
+
+// Cache keeps the loaded corpora of the same document, so that the models trained on it
+// with the same corpus settings, e.g. in a hyperparameter sweep, parse it only once.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[Key]*entry
+}
+
+type entry struct {
+	once   sync.Once
+	corpus Corpus
+	err    error
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		entries: make(map[Key]*entry),
+	}
+}
+
+type cacheKey struct{}
+
+// WithCache returns the context which makes Load share the corpora in cache.
+func WithCache(ctx context.Context, cache *Cache) context.Context {
+	return context.WithValue(ctx, cacheKey{}, cache)
+}
+
+// Load returns the corpus created by newCorpus(doc) and loaded with the co-occurrences of with.
+// If ctx has Cache by WithCache, the corpus loaded by the same key is shared instead, as long as
+// it implements Sharer. The corpus failing to load is not kept in the cache.
+func Load(
+	ctx context.Context,
+	key Key,
+	doc io.ReadSeeker,
+	newCorpus func(io.ReadSeeker) Corpus,
+	with *WithCooccurrence,
+	verbose *verbose.Verbose,
+	logBatch int,
+) (Corpus, error) {
+	cache, _ := ctx.Value(cacheKey{}).(*Cache)
+	if cache == nil {
+		c := newCorpus(doc)
+		if err := c.Load(ctx, with, verbose, logBatch); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+
+	if with != nil {
+		key.With, key.Cooccurrence = *with, true
+	}
+	cache.mu.Lock()
+	e, ok := cache.entries[key]
+	if !ok {
+		e = &entry{}
+		cache.entries[key] = e
+	}
+	cache.mu.Unlock()
+
+	loaded := false
+	e.once.Do(func() {
+		loaded = true
+		e.corpus = newCorpus(doc)
+		e.err = e.corpus.Load(ctx, with, verbose, logBatch)
+	})
+	if e.err != nil {
+		cache.mu.Lock()
+		if cache.entries[key] == e {
+			delete(cache.entries, key)
+		}
+		cache.mu.Unlock()
+		return nil, e.err
+	}
+	if loaded {
+		return e.corpus, nil
+	}
+	sharer, ok := e.corpus.(Sharer)
+	if !ok {
+		c := newCorpus(doc)
+		if err := c.Load(ctx, with, verbose, logBatch); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return sharer.Share(doc), nil
+}
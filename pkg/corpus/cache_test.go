@@ -0,0 +1,97 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corpus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+type fakeCorpus struct {
+	doc   io.ReadSeeker
+	loads *int
+}
+
+func (c *fakeCorpus) IndexedDoc() []int                                 { return nil }
+func (c *fakeCorpus) BatchWords(context.Context, chan []int, int) error { return nil }
+func (c *fakeCorpus) Dictionary() *dictionary.Dictionary                { return nil }
+func (c *fakeCorpus) Cooccurrence() *co.Cooccurrence                    { return nil }
+func (c *fakeCorpus) Len() int                                          { return 0 }
+func (c *fakeCorpus) FilteredLen() int                                  { return 0 }
+
+func (c *fakeCorpus) Load(ctx context.Context, _ *WithCooccurrence, _ *verbose.Verbose, _ int) error {
+	*c.loads++
+	return ctx.Err()
+}
+
+func (c *fakeCorpus) Share(doc io.ReadSeeker) Corpus {
+	shared := *c
+	shared.doc = doc
+	return &shared
+}
+
+func TestLoad(t *testing.T) {
+	loads := 0
+	newCorpus := func(doc io.ReadSeeker) Corpus {
+		return &fakeCorpus{doc: doc, loads: &loads}
+	}
+	load := func(ctx context.Context, key Key, with *WithCooccurrence) Corpus {
+		doc := strings.NewReader("a b c")
+		c, err := Load(ctx, key, doc, newCorpus, with, verbose.New(false), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, doc, c.(*fakeCorpus).doc)
+		return c
+	}
+
+	load(context.Background(), Key{}, nil)
+	load(context.Background(), Key{}, nil)
+	assert.Equal(t, 2, loads)
+
+	loads = 0
+	ctx := WithCache(context.Background(), NewCache())
+	load(ctx, Key{}, nil)
+	load(ctx, Key{}, nil)
+	assert.Equal(t, 1, loads)
+	load(ctx, Key{MinCount: 5}, nil)
+	load(ctx, Key{}, &WithCooccurrence{CountType: co.Increment, Window: 5})
+	load(ctx, Key{}, &WithCooccurrence{CountType: co.Increment, Window: 5})
+	assert.Equal(t, 3, loads)
+}
+
+func TestLoadError(t *testing.T) {
+	loads := 0
+	newCorpus := func(doc io.ReadSeeker) Corpus {
+		return &fakeCorpus{doc: doc, loads: &loads}
+	}
+	cache := NewCache()
+	canceled, cancel := context.WithCancel(WithCache(context.Background(), cache))
+	cancel()
+	_, err := Load(canceled, Key{}, strings.NewReader(""), newCorpus, nil, verbose.New(false), 1)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// the failed corpus is not kept, so that it's loaded again.
+	_, err = Load(WithCache(context.Background(), cache), Key{}, strings.NewReader(""), newCorpus, nil, verbose.New(false), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, loads)
+}
@@ -0,0 +1,112 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conllu
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// InverseSuffix marks the context of the modifier which sees the head.
+	InverseSuffix = "-1"
+)
+
+type token struct {
+	form   string
+	head   int
+	deprel string
+}
+
+// Contexts reads the sentences in CoNLL-U format from r, and calls fn for the dependency-based
+// contexts of each word (Levy and Goldberg 2014). For the modifier m of the head h with
+// the relation rel, h has the context m/rel and m has the context h/rel-1.
+// The multiword tokens, the empty nodes and the root relation are skipped.
+func Contexts(r io.Reader, toLower bool, fn func(word, context string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var (
+		line   int
+		tokens []token
+	)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			if err := emit(tokens, fn); err != nil {
+				return errors.Wrapf(err, "sentence ending at line %d", line)
+			}
+			tokens = tokens[:0]
+			continue
+		}
+		if strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) != 10 {
+			return errors.Errorf("line %d: expected 10 fields but got %d", line, len(fields))
+		}
+		if strings.ContainsAny(fields[0], "-.") {
+			continue
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil || id != len(tokens)+1 {
+			return errors.Errorf("line %d: invalid ID %q", line, fields[0])
+		}
+		head, err := strconv.Atoi(fields[6])
+		if err != nil || head < 0 {
+			return errors.Errorf("line %d: invalid HEAD %q", line, fields[6])
+		}
+		form := strings.ReplaceAll(fields[1], " ", "_")
+		if toLower {
+			form = strings.ToLower(form)
+		}
+		tokens = append(tokens, token{
+			form:   form,
+			head:   head,
+			deprel: fields[7],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if err := emit(tokens, fn); err != nil {
+		return errors.Wrapf(err, "sentence ending at line %d", line)
+	}
+	return nil
+}
+
+func emit(tokens []token, fn func(word, context string) error) error {
+	for _, m := range tokens {
+		if m.head == 0 {
+			continue
+		}
+		if m.head > len(tokens) {
+			return errors.Errorf("HEAD %d is out of the sentence", m.head)
+		}
+		h := tokens[m.head-1]
+		if err := fn(h.form, m.form+"/"+m.deprel); err != nil {
+			return err
+		}
+		if err := fn(m.form, h.form+"/"+m.deprel+InverseSuffix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conllu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContexts(t *testing.T) {
+	doc := strings.Join([]string{
+		"# text = Australian scientist discovers star",
+		"1\tAustralian\taustralian\tADJ\tJJ\t_\t2\tamod\t_\t_",
+		"2\tScientist\tscientist\tNOUN\tNN\t_\t3\tnsubj\t_\t_",
+		"3\tdiscovers\tdiscover\tVERB\tVBZ\t_\t0\troot\t_\t_",
+		"3.1\tfinds\tfind\tVERB\tVBZ\t_\t_\t_\t_\t_",
+		"4-5\tstar's\t_\t_\t_\t_\t_\t_\t_\t_",
+		"4\tstar\tstar\tNOUN\tNN\t_\t3\tobj\t_\t_",
+		"5\t's\t's\tPART\tPOS\t_\t4\tcase\t_\t_",
+		"",
+		"1\tHi\thi\tINTJ\tUH\t_\t0\troot\t_\t_",
+	}, "\n")
+
+	var got [][2]string
+	assert.NoError(t, Contexts(strings.NewReader(doc), true, func(word, context string) error {
+		got = append(got, [2]string{word, context})
+		return nil
+	}))
+	assert.Equal(t, [][2]string{
+		{"scientist", "australian/amod"},
+		{"australian", "scientist/amod-1"},
+		{"discovers", "scientist/nsubj"},
+		{"scientist", "discovers/nsubj-1"},
+		{"discovers", "star/obj"},
+		{"star", "discovers/obj-1"},
+		{"star", "'s/case"},
+		{"'s", "star/case-1"},
+	}, got)
+}
+
+func TestContextsError(t *testing.T) {
+	for name, doc := range map[string]string{
+		"fields": "1\tword\t0\troot",
+		"id":     "2\tword\tword\tX\tX\t_\t0\troot\t_\t_",
+		"head":   "1\tword\tword\tX\tX\t_\t3\tdep\t_\t_",
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, Contexts(strings.NewReader(doc), false, func(string, string) error {
+				return nil
+			}))
+		})
+	}
+}
@@ -15,6 +15,8 @@
 package corpus
 
 import (
+	"context"
+
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
 	"github.com/ynqa/wego/pkg/util/verbose"
@@ -22,11 +24,12 @@ import (
 
 type Corpus interface {
 	IndexedDoc() []int
-	BatchWords(chan []int, int) error
+	BatchWords(context.Context, chan []int, int) error
 	Dictionary() *dictionary.Dictionary
 	Cooccurrence() *co.Cooccurrence
 	Len() int
-	Load(*WithCooccurrence, *verbose.Verbose, int) error
+	FilteredLen() int
+	Load(context.Context, *WithCooccurrence, *verbose.Verbose, int) error
 }
 
 type WithCooccurrence struct {
@@ -103,6 +103,28 @@ func (f Filters) Any(id int, dic *dictionary.Dictionary) bool {
 	return b
 }
 
+// Vocab returns the number of words in dic which are not filtered out.
+func (f Filters) Vocab(dic *dictionary.Dictionary) int {
+	var n int
+	for id := 0; id < dic.Len(); id++ {
+		if !f.Any(id, dic) {
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the number of words on corpus which are not filtered out.
+func (f Filters) Len(dic *dictionary.Dictionary) int {
+	var n int
+	for id := 0; id < dic.Len(); id++ {
+		if !f.Any(id, dic) {
+			n += dic.IDFreq(id)
+		}
+	}
+	return n
+}
+
 type FilterFn func(int, *dictionary.Dictionary) bool
 
 func MaxCount(v int) FilterFn {
@@ -16,42 +16,77 @@ package dictionary
 
 import (
 	"sort"
-
-	"github.com/ynqa/wego/pkg/corpus/dictionary/node"
 )
 
-func (d *Dictionary) HuffnamTree(dim int) []*node.Node {
-	nodes := make([]*node.Node, d.maxid)
-	set := make([]*node.Node, d.maxid)
-	for i := 0; i < d.maxid; i++ {
-		n := &node.Node{
-			Val: d.IDFreq(i),
-		}
-		nodes[i] = n
-		set[i] = n
+// Huffman is the Huffman tree over the words, stored as flat arrays as in word2vec.c.
+// For the word, Codes holds the branch (0 or 1) taken at each inner node and Points holds
+// the index of the inner node, both from the root to the word. Inner nodes are indexed
+// in [0, Len()-1), so their vectors can be stored in one matrix.
+type Huffman struct {
+	Codes   []uint8
+	Points  []int
+	Offsets []int
+}
+
+// Huffman builds the Huffman tree from the frequencies of the words.
+func (d *Dictionary) Huffman() *Huffman {
+	n := d.maxid
+	if n == 0 {
+		return &Huffman{Offsets: []int{0}}
 	}
 
-	sort.SliceStable(nodes, func(i, j int) bool {
-		return nodes[i].Val < nodes[j].Val
+	size := 2*n - 1
+	count, parent, binary := make([]int, size), make([]int, size), make([]uint8, size)
+	copy(count, d.cfs[:n])
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return count[order[i]] < count[order[j]]
 	})
-	for len(nodes) > 1 {
-		left, right := nodes[0], nodes[1]
-		merged := &node.Node{
-			Val:    left.Val + right.Val,
-			Vector: make([]float64, dim),
-		}
-		left.Code, right.Code = 0, 1
-		left.Parent, right.Parent = merged, merged
 
-		nodes = nodes[2:]
-		idx := sort.Search(len(nodes), func(i int) bool {
-			return nodes[i].Val >= merged.Val
-		})
+	// The merged nodes are created in the ascending order of count, so that
+	// the smallest node is always at the head of either the leaves or the merged nodes.
+	leaf, merged, next := 0, n, n
+	pick := func() int {
+		if leaf < n && (merged >= next || count[order[leaf]] <= count[merged]) {
+			leaf++
+			return order[leaf-1]
+		}
+		merged++
+		return merged - 1
+	}
+	for ; next < size; next++ {
+		min1, min2 := pick(), pick()
+		count[next] = count[min1] + count[min2]
+		parent[min1], parent[min2] = next, next
+		binary[min2] = 1
+	}
 
-		nodes = append(nodes, &node.Node{})
-		copy(nodes[idx+1:], nodes[idx:])
-		nodes[idx] = merged
+	h := &Huffman{
+		Offsets: make([]int, n+1),
 	}
+	root := size - 1
+	for id := 0; id < n; id++ {
+		start := len(h.Codes)
+		for node := id; node != root; node = parent[node] {
+			h.Codes = append(h.Codes, binary[node])
+			h.Points = append(h.Points, parent[node]-n)
+		}
+		// reverse to be from the root.
+		for i, j := start, len(h.Codes)-1; i < j; i, j = i+1, j-1 {
+			h.Codes[i], h.Codes[j] = h.Codes[j], h.Codes[i]
+			h.Points[i], h.Points[j] = h.Points[j], h.Points[i]
+		}
+		h.Offsets[id+1] = len(h.Codes)
+	}
+	return h
+}
 
-	return set
+// Path returns the codes and the inner node indices of the word from the root.
+func (h *Huffman) Path(id int) ([]uint8, []int) {
+	s, e := h.Offsets[id], h.Offsets[id+1]
+	return h.Codes[s:e], h.Points[s:e]
 }
@@ -0,0 +1,54 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dictionary
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHuffman(t *testing.T) {
+	dic := New()
+	// a:5, b:1, c:1, d:2, e:3
+	dic.Add(strings.Fields("a a a a a b c d d e e e")...)
+	h := dic.Huffman()
+
+	depth := map[string]int{"a": 1, "b": 4, "c": 4, "d": 3, "e": 2}
+	seen := make(map[string]bool)
+	for word, d := range depth {
+		id, _ := dic.ID(word)
+		codes, points := h.Path(id)
+		assert.Len(t, codes, d, word)
+		assert.Len(t, points, d, word)
+		// the root is the last inner node.
+		assert.Equal(t, dic.Len()-2, points[0], word)
+		for _, p := range points {
+			assert.True(t, 0 <= p && p < dic.Len()-1, word)
+		}
+		key := string(codes)
+		assert.False(t, seen[key], word)
+		seen[key] = true
+	}
+}
+
+func TestHuffmanSingleWord(t *testing.T) {
+	dic := New()
+	dic.Add("a", "a")
+	codes, points := dic.Huffman().Path(0)
+	assert.Empty(t, codes)
+	assert.Empty(t, points)
+}
@@ -15,6 +15,7 @@
 package fs
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -51,11 +52,19 @@ func New(r io.ReadSeeker, toLower bool, maxCount, minCount int) corpus.Corpus {
 	}
 }
 
+// Share returns the corpus with the same words, which reads doc instead.
+func (c *Corpus) Share(doc io.ReadSeeker) corpus.Corpus {
+	shared := *c
+	shared.doc = doc
+	return &shared
+}
+
 func (c *Corpus) IndexedDoc() []int {
 	return nil
 }
 
-func (c *Corpus) BatchWords(ch chan []int, batchSize int) error {
+func (c *Corpus) BatchWords(ctx context.Context, ch chan []int, batchSize int) error {
+	defer close(ch)
 	cursor, ids := 0, make([]int, batchSize)
 	if err := cpsutil.ReadWord(c.doc, func(word string) error {
 		if c.toLower {
@@ -70,7 +79,11 @@ func (c *Corpus) BatchWords(ch chan []int, batchSize int) error {
 		ids[cursor] = id
 		cursor++
 		if cursor == batchSize {
-			ch <- ids
+			select {
+			case ch <- ids:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 			cursor, ids = 0, make([]int, batchSize)
 		}
 		return nil
@@ -79,8 +92,11 @@ func (c *Corpus) BatchWords(ch chan []int, batchSize int) error {
 	}
 
 	// send left words
-	ch <- ids[:cursor]
-	close(ch)
+	select {
+	case ch <- ids[:cursor]:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 	return nil
 }
 
@@ -96,9 +112,18 @@ func (c *Corpus) Len() int {
 	return c.maxLen
 }
 
-func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, logBatch int) error {
+func (c *Corpus) FilteredLen() int {
+	return c.filters.Len(c.dic)
+}
+
+func (c *Corpus) Load(ctx context.Context, with *corpus.WithCooccurrence, verbose *verbose.Verbose, logBatch int) error {
 	clk := clock.New()
 	if err := cpsutil.ReadWord(c.doc, func(word string) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
 		if c.toLower {
 			word = strings.ToLower(word)
 		}
@@ -131,6 +156,11 @@ func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, l
 		}
 
 		if err = cpsutil.ReadWordWithForwardContext(c.doc, with.Window, func(w1, w2 string) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
 			id1, _ := c.dic.ID(w1)
 			id2, _ := c.dic.ID(w2)
 			if err := c.cooc.Add(id1, id2); err != nil {
@@ -15,6 +15,7 @@
 package memory
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -53,6 +54,13 @@ func New(doc io.ReadSeeker, toLower bool, maxCount, minCount int) corpus.Corpus
 	}
 }
 
+// Share returns the corpus with the same words, which reads doc instead.
+func (c *Corpus) Share(doc io.ReadSeeker) corpus.Corpus {
+	shared := *c
+	shared.doc = doc
+	return &shared
+}
+
 func (c *Corpus) IndexedDoc() []int {
 	var res []int
 	for _, id := range c.idoc {
@@ -64,7 +72,7 @@ func (c *Corpus) IndexedDoc() []int {
 	return res
 }
 
-func (c *Corpus) BatchWords(chan []int, int) error {
+func (c *Corpus) BatchWords(context.Context, chan []int, int) error {
 	return nil
 }
 
@@ -80,9 +88,18 @@ func (c *Corpus) Len() int {
 	return c.maxLen
 }
 
-func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, logBatch int) error {
+func (c *Corpus) FilteredLen() int {
+	return c.filters.Len(c.dic)
+}
+
+func (c *Corpus) Load(ctx context.Context, with *corpus.WithCooccurrence, verbose *verbose.Verbose, logBatch int) error {
 	clk := clock.New()
 	if err := cpsutil.ReadWord(c.doc, func(word string) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
 		if c.toLower {
 			word = strings.ToLower(word)
 		}
@@ -117,6 +134,11 @@ func (c *Corpus) Load(with *corpus.WithCooccurrence, verbose *verbose.Verbose, l
 		}
 
 		for i := 0; i < len(c.idoc); i++ {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
 			for j := i + 1; j < len(c.idoc) && j <= i+with.Window; j++ {
 				if err = c.cooc.Add(c.idoc[i], c.idoc[j]); err != nil {
 					return err
@@ -0,0 +1,224 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pairs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+// Corpus reads the explicit (word, context) pairs, one pair per line separated by spaces:
+//
+//	<word> <context>
+//
+// The words and the contexts have the separate dictionaries, and a pair is filtered out
+// if either of them is filtered out.
+type Corpus struct {
+	r io.ReadSeeker
+
+	words    *dictionary.Dictionary
+	contexts *dictionary.Dictionary
+	maxLen   int
+	// filteredLen is the number of pairs which are not filtered out.
+	filteredLen int
+
+	// pairs stores the word and context ids alternately if inMemory.
+	inMemory bool
+	pairs    []int
+
+	toLower bool
+	filters cpsutil.Filters
+}
+
+func New(r io.ReadSeeker, inMemory, toLower bool, maxCount, minCount int) *Corpus {
+	return &Corpus{
+		r:        r,
+		words:    dictionary.New(),
+		contexts: dictionary.New(),
+
+		inMemory: inMemory,
+
+		toLower: toLower,
+		filters: cpsutil.Filters{
+			cpsutil.MaxCount(maxCount),
+			cpsutil.MinCount(minCount),
+		},
+	}
+}
+
+// ReadPair calls fn for each pair on r. It fails for the line which doesn't have exactly two fields.
+func ReadPair(r io.ReadSeeker, fn func(word, context string) error) error {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "failed to seek the pairs")
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var line int
+	for scanner.Scan() {
+		line++
+		fields := strings.FieldsFunc(scanner.Text(), separator)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return errors.Errorf("line %d: expected <word> <context> but got %d fields", line, len(fields))
+		}
+		if err := fn(fields[0], fields[1]); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// separator reports whether r separates the word and the context on a line. The other Unicode
+// spaces, e.g. the non-breaking space in the forms of CoNLL-U, are kept in the words.
+func separator(r rune) bool {
+	switch r {
+	case ' ', '\t', '\v', '\f', '\r':
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Corpus) WordDictionary() *dictionary.Dictionary {
+	return c.words
+}
+
+func (c *Corpus) ContextDictionary() *dictionary.Dictionary {
+	return c.contexts
+}
+
+// Len returns the number of pairs on corpus.
+func (c *Corpus) Len() int {
+	return c.maxLen
+}
+
+// FilteredLen returns the number of pairs on corpus which are not filtered out.
+func (c *Corpus) FilteredLen() int {
+	return c.filteredLen
+}
+
+func (c *Corpus) filtered(word, context int) bool {
+	return c.filters.Any(word, c.words) || c.filters.Any(context, c.contexts)
+}
+
+func (c *Corpus) Load(ctx context.Context, verbose *verbose.Verbose, logBatch int) error {
+	clk := clock.New()
+	if err := ReadPair(c.r, func(word, context string) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if c.toLower {
+			word, context = strings.ToLower(word), strings.ToLower(context)
+		}
+
+		c.words.Add(word)
+		c.contexts.Add(context)
+		c.maxLen++
+		if c.inMemory {
+			w, _ := c.words.ID(word)
+			cid, _ := c.contexts.ID(context)
+			c.pairs = append(c.pairs, w, cid)
+		}
+		verbose.Do(func() {
+			if c.maxLen%logBatch == 0 {
+				fmt.Printf("read %d pairs %v\r", c.maxLen, clk.AllElapsed())
+			}
+		})
+		return nil
+	}); err != nil {
+		return err
+	}
+	verbose.Do(func() {
+		fmt.Printf("read %d pairs %v\r\n", c.maxLen, clk.AllElapsed())
+	})
+
+	// the filters depend on the counts over the whole corpus, so count the kept pairs after that.
+	return c.readIDs(ctx, func(int, int) error {
+		c.filteredLen++
+		return nil
+	})
+}
+
+// readIDs calls fn for each pair which is not filtered out.
+func (c *Corpus) readIDs(ctx context.Context, fn func(word, context int) error) error {
+	if c.inMemory {
+		for i := 0; i < len(c.pairs); i += 2 {
+			if i%2048 == 0 && ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if c.filtered(c.pairs[i], c.pairs[i+1]) {
+				continue
+			}
+			if err := fn(c.pairs[i], c.pairs[i+1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ReadPair(c.r, func(word, context string) error {
+		if c.toLower {
+			word, context = strings.ToLower(word), strings.ToLower(context)
+		}
+		w, _ := c.words.ID(word)
+		cid, _ := c.contexts.ID(context)
+		if c.filtered(w, cid) {
+			return nil
+		}
+		return fn(w, cid)
+	})
+}
+
+// BatchPairs sends the pairs which are not filtered out into ch, batchSize pairs at a time.
+// Each batch has the word and context ids alternately. ch is closed at the end.
+func (c *Corpus) BatchPairs(ctx context.Context, ch chan []int, batchSize int) error {
+	defer close(ch)
+	ids := make([]int, 0, batchSize*2)
+	if err := c.readIDs(ctx, func(word, context int) error {
+		ids = append(ids, word, context)
+		if len(ids) == batchSize*2 {
+			select {
+			case ch <- ids:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			ids = make([]int, 0, batchSize*2)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// send left pairs
+	select {
+	case ch <- ids:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
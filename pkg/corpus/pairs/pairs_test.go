@@ -0,0 +1,99 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pairs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/conllu"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+type errSeeker struct {
+	*strings.Reader
+}
+
+func (errSeeker) Seek(int64, int) (int64, error) {
+	return 0, errors.New("seek")
+}
+
+func TestReadPair(t *testing.T) {
+	var got [][2]string
+	fn := func(word, context string) error {
+		got = append(got, [2]string{word, context})
+		return nil
+	}
+
+	r := strings.NewReader("a x\n\nb  y\n")
+	assert.NoError(t, ReadPair(r, fn))
+	assert.Equal(t, [][2]string{{"a", "x"}, {"b", "y"}}, got)
+
+	// r is read from the start again.
+	got = nil
+	assert.NoError(t, ReadPair(r, fn))
+	assert.Equal(t, [][2]string{{"a", "x"}, {"b", "y"}}, got)
+
+	assert.EqualError(t, ReadPair(strings.NewReader("a x\nb y z\n"), fn), "line 2: expected <word> <context> but got 3 fields")
+	assert.EqualError(t, ReadPair(errSeeker{strings.NewReader("a x\n")}, fn), "failed to seek the pairs: seek")
+}
+
+func TestReadPairFromConllu(t *testing.T) {
+	doc := strings.Join([]string{
+		"1\t10\u00a0000\t10\u00a0000\tNUM\tCD\t_\t2\tnummod\t_\t_",
+		"2\tNew York\tNew York\tPROPN\tNNP\t_\t0\troot\t_\t_",
+	}, "\n")
+	var buf bytes.Buffer
+	assert.NoError(t, conllu.Contexts(strings.NewReader(doc), false, func(word, context string) error {
+		_, err := fmt.Fprintf(&buf, "%s %s\n", word, context)
+		return err
+	}))
+
+	var got [][2]string
+	assert.NoError(t, ReadPair(bytes.NewReader(buf.Bytes()), func(word, context string) error {
+		got = append(got, [2]string{word, context})
+		return nil
+	}))
+	assert.Equal(t, [][2]string{
+		{"New_York", "10\u00a0000/nummod"},
+		{"10\u00a0000", "New_York/nummod-1"},
+	}, got)
+}
+
+func TestBatchPairs(t *testing.T) {
+	doc := "a x\nb y\nA y\nc x\na x\n"
+	for _, inMemory := range []bool{true, false} {
+		c := New(strings.NewReader(doc), inMemory, true, -1, 2)
+		assert.NoError(t, c.Load(context.Background(), verbose.New(false), 100))
+		assert.Equal(t, 5, c.Len())
+		// b and c are filtered out by min count.
+		assert.Equal(t, 3, c.FilteredLen())
+
+		ch := make(chan []int, 10)
+		assert.NoError(t, c.BatchPairs(context.Background(), ch, 2))
+		var got [][]int
+		for ids := range ch {
+			got = append(got, ids)
+		}
+		// a=0, x=0 and y=1.
+		assert.Equal(t, [][]int{{0, 0, 0, 1}, {0, 0}}, got, "inMemory=%v", inMemory)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/num"
+)
+
+const (
+	// maxDim bounds dim in the header, so that a broken or hostile header can't make the loaders
+	// allocate an arbitrary amount of memory.
+	maxDim = 1 << 16
+	// maxPrealloc bounds the capacity allocated by count in the header before reading the words.
+	maxPrealloc = 1 << 16
+)
+
+// LoadBinary reads the embeddings in the binary format of word2vec: the header line
+// `<count> <dim>`, and then each word followed by a space and dim little-endian float32.
+func LoadBinary(r io.Reader) (Embeddings, error) {
+	return LoadBinaryOf[float64](r)
+}
+
+// LoadBinaryOf reads the embeddings in the binary format whose elements are stored as T.
+func LoadBinaryOf[T num.Float](r io.Reader) (EmbeddingsOf[T], error) {
+	reader := bufio.NewReader(r)
+	header, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the header")
+	}
+	count, dim, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+
+	capacity := count
+	if capacity > maxPrealloc {
+		capacity = maxPrealloc
+	}
+	embs := make(EmbeddingsOf[T], 0, capacity)
+	buf := make([]byte, 4*dim)
+	for i := 0; i < count; i++ {
+		word, err := readBinaryWord(reader)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the word %d of %d", i+1, count)
+		}
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, errors.Wrapf(err, "failed to read the vector of %s", word)
+		}
+		vec := make([]T, dim)
+		for j := range vec {
+			vec[j] = T(math.Float32frombits(binary.LittleEndian.Uint32(buf[4*j:])))
+		}
+		emb := EmbeddingOf[T]{
+			Word:   word,
+			Dim:    dim,
+			Vector: vec,
+			Norm:   embutil.Norm(vec),
+		}
+		if err := emb.Validate(); err != nil {
+			return nil, err
+		}
+		embs = append(embs, emb)
+	}
+	return embs, nil
+}
+
+// parseHeader parses the header line `<count> <dim>`.
+func parseHeader(line string) (int, int, error) {
+	fs := strings.Fields(line)
+	if len(fs) != 2 {
+		return 0, 0, errors.Errorf("invalid header: expected <count> <dim> but got %q", strings.TrimSpace(line))
+	}
+	count, err := strconv.Atoi(fs[0])
+	if err != nil || count < 0 {
+		return 0, 0, errors.Errorf("invalid count in the header: %q", fs[0])
+	}
+	dim, err := strconv.Atoi(fs[1])
+	if err != nil || dim <= 0 {
+		return 0, 0, errors.Errorf("invalid dim in the header: %q", fs[1])
+	} else if dim > maxDim {
+		return 0, 0, errors.Errorf("dim in the header must be <= %d: %d", maxDim, dim)
+	}
+	return count, dim, nil
+}
+
+// readBinaryWord reads the word until the space, skipping the newline after the previous
+// vector, which is written by word2vec but not by some other tools.
+func readBinaryWord(r *bufio.Reader) (string, error) {
+	var b strings.Builder
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if c == ' ' {
+			if b.Len() == 0 {
+				return "", errors.New("empty word")
+			}
+			return b.String(), nil
+		}
+		if c == '\n' && b.Len() == 0 {
+			continue
+		}
+		b.WriteByte(c)
+	}
+}
+
+// SaveBinary writes the embeddings in the binary format of word2vec as LoadBinary reads.
+// The elements are converted into float32.
+func SaveBinary[T num.Float](w io.Writer, embs EmbeddingsOf[T]) error {
+	if err := embs.Validate(); err != nil {
+		return err
+	}
+	var dim int
+	if len(embs) > 0 {
+		dim = embs[0].Dim
+	}
+	writer := bufio.NewWriter(w)
+	if err := WriteBinaryHeader(writer, len(embs), dim); err != nil {
+		return err
+	}
+	for _, emb := range embs {
+		if err := WriteBinary(writer, emb.Word, emb.Vector); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// WriteBinaryHeader writes the header line of the binary format.
+func WriteBinaryHeader(w io.Writer, count, dim int) error {
+	_, err := fmt.Fprintf(w, "%d %d\n", count, dim)
+	return err
+}
+
+// WriteBinary writes a word and its vector in the binary format, with the newline at the end
+// as word2vec does.
+func WriteBinary[T num.Float](w io.Writer, word string, vec []T) error {
+	if word == "" || strings.ContainsAny(word, " \n") {
+		return errors.Errorf("invalid word for the binary format: %q", word)
+	}
+	buf := make([]byte, len(word)+1+4*len(vec)+1)
+	n := copy(buf, word)
+	buf[n] = ' '
+	n++
+	for _, v := range vec {
+		binary.LittleEndian.PutUint32(buf[n:], math.Float32bits(float32(v)))
+		n += 4
+	}
+	buf[n] = '\n'
+	_, err := w.Write(buf)
+	return err
+}
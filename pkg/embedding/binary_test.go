@@ -0,0 +1,87 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveBinary(t *testing.T) {
+	embs, err := Load(bytes.NewReader([]byte("apple 1 0.5\nbanana -1 0.1\n")))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, SaveBinary(&buf, embs))
+	assert.Equal(t, "2 2\napple ", buf.String()[:10])
+	assert.Len(t, buf.Bytes(), len("2 2\napple banana ")+2*(8+1))
+
+	loaded, err := LoadBinary(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, loaded, 2)
+	for i, emb := range loaded {
+		assert.Equal(t, embs[i].Word, emb.Word)
+		assert.Equal(t, 2, emb.Dim)
+		assert.InDeltaSlice(t, embs[i].Vector, emb.Vector, 1e-7)
+	}
+}
+
+func TestLoadBinary(t *testing.T) {
+	vec := func(vs ...float32) []byte {
+		b := make([]byte, 4*len(vs))
+		for i, v := range vs {
+			binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+		}
+		return b
+	}
+
+	// without the newline after the vectors as gensim writes.
+	var buf bytes.Buffer
+	buf.WriteString("2 3\n")
+	buf.WriteString("café ")
+	buf.Write(vec(1, 2, 3))
+	buf.WriteString("new york ")
+	buf.Write(vec(-1, 0, 0.25))
+	embs, err := LoadBinaryOf[float32](&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "café", embs[0].Word)
+	assert.Equal(t, []float32{1, 2, 3}, embs[0].Vector)
+	assert.Equal(t, "new york", embs[1].Word)
+	assert.Equal(t, []float32{-1, 0, 0.25}, embs[1].Vector)
+
+	for _, contents := range []string{
+		"",
+		"2\n",
+		"x 3\n",
+		"1 0\n",
+		"1 1000000000\napple ",
+		"1000000000000 3\napple " + string(vec(1, 2, 3)),
+		"2 3\napple " + string(vec(1, 2, 3)),
+		"1 3\napple " + string(vec(1, 2)),
+	} {
+		_, err := LoadBinary(bytes.NewReader([]byte(contents)))
+		assert.Error(t, err, contents)
+	}
+}
+
+func TestWriteBinary(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, WriteBinary(&buf, "new york", []float64{1}))
+	assert.Error(t, WriteBinary(&buf, "", []float64{1}))
+}
@@ -16,6 +16,7 @@ package embedding
 
 import (
 	"bufio"
+	"fmt"
 	"io"
 	"strconv"
 	"strings"
@@ -23,16 +24,20 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/num"
 )
 
-type Embedding struct {
+// EmbeddingOf is the word and its vector whose elements are stored as T.
+type EmbeddingOf[T num.Float] struct {
 	Word   string
 	Dim    int
-	Vector []float64
+	Vector []T
 	Norm   float64
 }
 
-func (e Embedding) Validate() error {
+type Embedding = EmbeddingOf[float64]
+
+func (e EmbeddingOf[T]) Validate() error {
 	if e.Word == "" {
 		return errors.New("Word is empty")
 	} else if e.Dim == 0 || len(e.Vector) == 0 {
@@ -43,22 +48,24 @@ func (e Embedding) Validate() error {
 	return nil
 }
 
-type Embeddings []Embedding
+type EmbeddingsOf[T num.Float] []EmbeddingOf[T]
+
+type Embeddings = EmbeddingsOf[float64]
 
-func (embs Embeddings) Empty() bool {
+func (embs EmbeddingsOf[T]) Empty() bool {
 	return len(embs) == 0
 }
 
-func (embs Embeddings) Find(word string) (Embedding, bool) {
+func (embs EmbeddingsOf[T]) Find(word string) (EmbeddingOf[T], bool) {
 	for _, emb := range embs {
 		if word == emb.Word {
 			return emb, true
 		}
 	}
-	return Embedding{}, false
+	return EmbeddingOf[T]{}, false
 }
 
-func (embs Embeddings) Validate() error {
+func (embs EmbeddingsOf[T]) Validate() error {
 	if len(embs) > 0 {
 		dim := embs[0].Dim
 		for _, emb := range embs {
@@ -71,8 +78,13 @@ func (embs Embeddings) Validate() error {
 }
 
 func Load(r io.Reader) (Embeddings, error) {
-	var embs Embeddings
-	if err := parse(r, func(emb Embedding) error {
+	return LoadOf[float64](r)
+}
+
+// LoadOf reads the embeddings whose elements are stored as T.
+func LoadOf[T num.Float](r io.Reader) (EmbeddingsOf[T], error) {
+	var embs EmbeddingsOf[T]
+	if err := parse(r, func(emb EmbeddingOf[T]) error {
 		if err := emb.Validate(); err != nil {
 			return err
 		}
@@ -84,45 +96,113 @@ func Load(r io.Reader) (Embeddings, error) {
 	return embs, nil
 }
 
-func parse(r io.Reader, op func(Embedding) error) error {
+// Save writes the embeddings in the same text format as Load reads.
+func Save[T num.Float](w io.Writer, embs EmbeddingsOf[T]) error {
+	writer := bufio.NewWriter(w)
+	for _, emb := range embs {
+		fmt.Fprintf(writer, "%v ", emb.Word)
+		for _, v := range emb.Vector {
+			fmt.Fprintf(writer, "%f ", v)
+		}
+		fmt.Fprintln(writer)
+	}
+	return writer.Flush()
+}
+
+func parse[T num.Float](r io.Reader, op func(EmbeddingOf[T]) error) error {
 	s := bufio.NewScanner(r)
-	for s.Scan() {
-		line := s.Text()
+	var (
+		n, count, dim int
+		header        bool
+	)
+	for lineNum := 1; s.Scan(); lineNum++ {
+		line := strings.TrimRight(s.Text(), "\r")
 		if strings.HasPrefix(line, " ") {
 			continue
 		}
-		emb, err := parseLine(line)
+		// the header `<count> <dim>` of word2vec and fastText is only on the first line.
+		if lineNum == 1 && isHeader(line) {
+			var err error
+			if count, dim, err = parseHeader(line); err != nil {
+				return err
+			}
+			header = true
+			continue
+		}
+		emb, err := parseLineOf[T](line, dim)
 		if err != nil {
-			return err
+			return errors.Wrapf(err, "line %d", lineNum)
 		}
 		if err := op(emb); err != nil {
 			return err
 		}
+		n++
 	}
 	if err := s.Err(); err != nil && err != io.EOF {
 		return errors.Wrapf(err, "failed to scan")
 	}
+	if header && n != count {
+		return errors.Errorf("the header says %d words but got %d", count, n)
+	}
 	return nil
 }
 
+// separator reports whether r separates the word and the values on a line. The other Unicode
+// spaces, e.g. the non-breaking space, are kept in the words.
+func separator(r rune) bool {
+	switch r {
+	case ' ', '\t', '\v', '\f', '\r':
+		return true
+	default:
+		return false
+	}
+}
+
+func fields(line string) []string {
+	return strings.FieldsFunc(line, separator)
+}
+
+// isHeader reports whether line consists of two integers like `<count> <dim>`.
+func isHeader(line string) bool {
+	fs := fields(line)
+	if len(fs) != 2 {
+		return false
+	}
+	for _, f := range fs {
+		if _, err := strconv.Atoi(f); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func parseLine(line string) (Embedding, error) {
-	slice := strings.Fields(line)
+	return parseLineOf[float64](line, 0)
+}
+
+// parseLineOf parses the word and its vector. If dim is given by the header, the line must have
+// dim values.
+func parseLineOf[T num.Float](line string, dim int) (EmbeddingOf[T], error) {
+	slice := fields(line)
 	if len(slice) < 2 {
-		return Embedding{}, errors.New("Must be over 2 lenghth for word and vector elems")
+		return EmbeddingOf[T]{}, errors.New("Must be over 2 lenghth for word and vector elems")
 	}
 	word := slice[0]
 	vector := slice[1:]
-	dim := len(vector)
+	if dim > 0 && len(vector) != dim {
+		return EmbeddingOf[T]{}, errors.Errorf("the header says dim %d but %s has %d values", dim, word, len(vector))
+	}
+	dim = len(vector)
 
-	vec := make([]float64, dim)
+	vec := make([]T, dim)
 	for k, elem := range vector {
 		val, err := strconv.ParseFloat(elem, 64)
 		if err != nil {
-			return Embedding{}, err
+			return EmbeddingOf[T]{}, err
 		}
-		vec[k] = val
+		vec[k] = T(val)
 	}
-	return Embedding{
+	return EmbeddingOf[T]{
 		Word:   word,
 		Dim:    dim,
 		Vector: vec,
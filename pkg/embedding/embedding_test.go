@@ -93,3 +93,83 @@ func TestParseLine(t *testing.T) {
 		})
 	}
 }
+
+func TestSave(t *testing.T) {
+	embs, err := Load(bytes.NewReader([]byte("apple 1 0.5\nbanana -1 0\n")))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, Save(&buf, embs))
+	assert.Equal(t, "apple 1.000000 0.500000 \nbanana -1.000000 0.000000 \n", buf.String())
+
+	loaded, err := Load(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, embs, loaded)
+}
+
+func TestLoadHeader(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		words    []string
+		dim      int
+		err      bool
+	}{
+		{
+			name:     "header",
+			contents: "2 3\napple 1 1 1\nbanana 1 0 1\n",
+			words:    []string{"apple", "banana"},
+			dim:      3,
+		},
+		{
+			name:     "header with trailing spaces and CRLF",
+			contents: "2 3 \r\napple 1 1 1 \r\nbanana 1 0 1 \r\n",
+			words:    []string{"apple", "banana"},
+			dim:      3,
+		},
+		{
+			name:     "numeric word after the first line",
+			contents: "apple 1\n400000 3\n",
+			words:    []string{"apple", "400000"},
+			dim:      1,
+		},
+		{
+			name:     "unicode spaces in words",
+			contents: "2 2\nnew\u00a0york 1 2\nfoo\u3000bar\u2009baz 3 4\n",
+			words:    []string{"new\u00a0york", "foo\u3000bar\u2009baz"},
+			dim:      2,
+		},
+		{
+			name:     "different dim from the header",
+			contents: "2 3\napple 1 1 1\nbanana 1 0\n",
+			err:      true,
+		},
+		{
+			name:     "different count from the header",
+			contents: "3 3\napple 1 1 1\nbanana 1 0 1\n",
+			err:      true,
+		},
+		{
+			name:     "zero dim in the header",
+			contents: "2 0\napple 1\n",
+			err:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			embs, err := Load(bytes.NewReader([]byte(tc.contents)))
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			words := make([]string, len(embs))
+			for i, emb := range embs {
+				words[i] = emb.Word
+				assert.Equal(t, tc.dim, emb.Dim)
+			}
+			assert.Equal(t, tc.words, words)
+		})
+	}
+}
@@ -15,13 +15,10 @@
 package embutil
 
 import (
-	"math"
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
 )
 
-func Norm(vec []float64) float64 {
-	var n float64
-	for _, v := range vec {
-		n += v * v
-	}
-	return math.Sqrt(n)
+func Norm[T num.Float](vec []T) float64 {
+	return vecmath.Norm(vec)
 }
@@ -0,0 +1,120 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/util/num"
+)
+
+// Format is the file format of the embeddings.
+type Format = string
+
+const (
+	// Text is a word and its values separated by spaces per line.
+	Text Format = "text"
+	// Vec is Text with the header line `<count> <dim>`, as the .vec files of fastText.
+	Vec Format = "vec"
+	// Binary is the binary format of word2vec, which is read by LoadBinary.
+	Binary Format = "bin"
+)
+
+func InvalidFormatError(format Format) error {
+	return errors.Errorf("invalid vector format: %s not in %s|%s|%s", format, Text, Vec, Binary)
+}
+
+// FormatOf returns the format by the extension of path: Binary for .bin, Vec for .vec, and Text
+// otherwise.
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".bin":
+		return Binary
+	case ".vec":
+		return Vec
+	default:
+		return Text
+	}
+}
+
+// ValidateFormat returns the error if format is not supported.
+func ValidateFormat(format Format) error {
+	switch format {
+	case Text, Vec, Binary:
+		return nil
+	default:
+		return InvalidFormatError(format)
+	}
+}
+
+// LoadFile reads the embeddings in path by the format of its extension. Text and Vec are read
+// in the same way, since the header line is detected by Load.
+func LoadFile(path string) (Embeddings, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if FormatOf(path) == Binary {
+		return LoadBinary(f)
+	}
+	return Load(f)
+}
+
+// SaveFile creates path with its directory, and writes the embeddings in the format of its
+// extension.
+func SaveFile[T num.Float](path string, embs EmbeddingsOf[T]) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := SaveFormat(f, embs, FormatOf(path)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// SaveFormat writes the embeddings in format.
+func SaveFormat[T num.Float](w io.Writer, embs EmbeddingsOf[T], format Format) error {
+	switch format {
+	case Text:
+		return Save(w, embs)
+	case Vec:
+		if err := embs.Validate(); err != nil {
+			return err
+		}
+		var dim int
+		if len(embs) > 0 {
+			dim = embs[0].Dim
+		}
+		if err := WriteBinaryHeader(w, len(embs), dim); err != nil {
+			return err
+		}
+		return Save(w, embs)
+	case Binary:
+		return SaveBinary(w, embs)
+	default:
+		return InvalidFormatError(format)
+	}
+}
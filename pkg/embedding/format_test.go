@@ -0,0 +1,59 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package embedding
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveFile(t *testing.T) {
+	embs := Embeddings{
+		{Word: "apple", Dim: 2, Vector: []float64{1, 0.5}},
+		{Word: "banana", Dim: 2, Vector: []float64{-1, 0.25}},
+	}
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name   string
+		prefix string
+	}{
+		{name: "vectors.txt", prefix: "apple "},
+		{name: "vectors.vec", prefix: "2 2\napple "},
+		{name: "vectors.bin", prefix: "2 2\napple "},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, "out", tc.name)
+			assert.NoError(t, SaveFile(path, embs))
+			b, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.prefix, string(b[:len(tc.prefix)]))
+
+			loaded, err := LoadFile(path)
+			assert.NoError(t, err)
+			assert.Len(t, loaded, 2)
+			for i, emb := range loaded {
+				assert.Equal(t, embs[i].Word, emb.Word)
+				assert.InDeltaSlice(t, embs[i].Vector, emb.Vector, 1e-6)
+			}
+		})
+	}
+}
+
+func TestSaveFormat(t *testing.T) {
+	assert.Error(t, SaveFormat(os.Stdout, Embeddings{}, "csv"))
+}
@@ -0,0 +1,271 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluate
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+// SimilarityPair is the pair of the words with the similarity rated by humans.
+type SimilarityPair struct {
+	Word1, Word2 string
+	Score        float64
+}
+
+// AnalogyQuestion is the question A:B = C:D, which is answered by D.
+type AnalogyQuestion struct {
+	A, B, C, D string
+}
+
+// Result is the score of the vectors on a dataset with the number of the examples whose
+// words are found in the vectors. The examples with unknown words are not scored.
+type Result struct {
+	Score float64
+	Found int
+	Total int
+}
+
+// Coverage returns the ratio of the examples which are scored.
+func (r Result) Coverage() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Found) / float64(r.Total)
+}
+
+func fields(line string) []string {
+	return strings.FieldsFunc(line, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ','
+	})
+}
+
+func skip(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "" || strings.HasPrefix(line, "#")
+}
+
+// ReadSimilarity reads the word pairs from r, one `<word1> <word2> <score>` per line, which are
+// separated by spaces, tabs or commas, e.g. WordSim353 and SimLex-999 in the first three columns.
+// Empty lines, lines starting with # and the header line are skipped.
+func ReadSimilarity(r io.Reader) ([]SimilarityPair, error) {
+	var pairs []SimilarityPair
+	s := bufio.NewScanner(r)
+	for n, header := 1, true; s.Scan(); n++ {
+		line := s.Text()
+		if skip(line) {
+			continue
+		}
+		fs := fields(line)
+		if len(fs) < 3 {
+			return nil, errors.Errorf("line %d: expected <word1> <word2> <score> but got %q", n, line)
+		}
+		score, err := strconv.ParseFloat(fs[2], 64)
+		if err != nil {
+			if header {
+				header = false
+				continue
+			}
+			return nil, errors.Wrapf(err, "line %d: invalid score", n)
+		}
+		header = false
+		pairs = append(pairs, SimilarityPair{Word1: fs[0], Word2: fs[1], Score: score})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// ReadAnalogy reads the questions from r, one `<a> <b> <c> <d>` per line, in the format of
+// the analogy dataset of word2vec. The section lines starting with : are skipped.
+func ReadAnalogy(r io.Reader) ([]AnalogyQuestion, error) {
+	var questions []AnalogyQuestion
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if skip(line) || strings.HasPrefix(strings.TrimSpace(line), ":") {
+			continue
+		}
+		fs := strings.Fields(line)
+		if len(fs) != 4 {
+			return nil, errors.Errorf("line %d: expected <a> <b> <c> <d> but got %q", n, line)
+		}
+		questions = append(questions, AnalogyQuestion{A: fs[0], B: fs[1], C: fs[2], D: fs[3]})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// Index keeps the unit vectors of the embeddings to evaluate them.
+type Index struct {
+	words   []string
+	vectors [][]float64
+	ids     map[string]int
+}
+
+func NewIndex(embs embedding.Embeddings) *Index {
+	idx := &Index{
+		words:   make([]string, len(embs)),
+		vectors: make([][]float64, len(embs)),
+		ids:     make(map[string]int, len(embs)),
+	}
+	for i, emb := range embs {
+		vec := append([]float64(nil), emb.Vector...)
+		if norm := vecmath.Norm(vec); norm > 0 {
+			vecmath.Scale(1/norm, vec)
+		}
+		idx.words[i], idx.vectors[i] = emb.Word, vec
+		if _, ok := idx.ids[emb.Word]; !ok {
+			idx.ids[emb.Word] = i
+		}
+	}
+	return idx
+}
+
+// id looks up word as it is, and then in lower case.
+func (idx *Index) id(word string) (int, bool) {
+	if id, ok := idx.ids[word]; ok {
+		return id, true
+	}
+	id, ok := idx.ids[strings.ToLower(word)]
+	return id, ok
+}
+
+// Similarity returns the Spearman's rank correlation between the cosine similarities and
+// the scores of pairs.
+func (idx *Index) Similarity(pairs []SimilarityPair) Result {
+	res := Result{Total: len(pairs)}
+	var gold, pred []float64
+	for _, p := range pairs {
+		id1, ok1 := idx.id(p.Word1)
+		id2, ok2 := idx.id(p.Word2)
+		if !ok1 || !ok2 {
+			continue
+		}
+		gold = append(gold, p.Score)
+		pred = append(pred, vecmath.Dot(idx.vectors[id1], idx.vectors[id2]))
+	}
+	res.Found = len(gold)
+	res.Score = Spearman(gold, pred)
+	return res
+}
+
+// Analogy returns the accuracy of the answers of the questions by 3CosAdd: the nearest word of
+// B - A + C except A, B and C.
+func (idx *Index) Analogy(questions []AnalogyQuestion) Result {
+	res := Result{Total: len(questions)}
+	if len(idx.vectors) == 0 {
+		return res
+	}
+	correct := 0
+	query := make([]float64, len(idx.vectors[0]))
+	for _, q := range questions {
+		a, okA := idx.id(q.A)
+		b, okB := idx.id(q.B)
+		c, okC := idx.id(q.C)
+		d, okD := idx.id(q.D)
+		if !okA || !okB || !okC || !okD {
+			continue
+		}
+		res.Found++
+		vecmath.Zero(query)
+		vecmath.Axpy(1, idx.vectors[b], query)
+		vecmath.Axpy(-1, idx.vectors[a], query)
+		vecmath.Axpy(1, idx.vectors[c], query)
+		best, bestSim := -1, math.Inf(-1)
+		for i, vec := range idx.vectors {
+			if i == a || i == b || i == c {
+				continue
+			}
+			if sim := vecmath.Dot(query, vec); sim > bestSim {
+				best, bestSim = i, sim
+			}
+		}
+		if best == d {
+			correct++
+		}
+	}
+	if res.Found > 0 {
+		res.Score = float64(correct) / float64(res.Found)
+	}
+	return res
+}
+
+// Spearman returns the Spearman's rank correlation coefficient of x and y, where the tied
+// values get the mean of their ranks. It returns 0 if x or y is constant.
+func Spearman(x, y []float64) float64 {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0
+	}
+	return pearson(ranks(x), ranks(y))
+}
+
+func ranks(x []float64) []float64 {
+	order := make([]int, len(x))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return x[order[i]] < x[order[j]]
+	})
+	res := make([]float64, len(x))
+	for i := 0; i < len(order); {
+		j := i
+		for j+1 < len(order) && x[order[j+1]] == x[order[i]] {
+			j++
+		}
+		// the mean of the ranks i+1, ..., j+1.
+		rank := float64(i+j)/2 + 1
+		for k := i; k <= j; k++ {
+			res[order[k]] = rank
+		}
+		i = j + 1
+	}
+	return res
+}
+
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx, my = mx/n, my/n
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
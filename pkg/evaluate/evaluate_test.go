@@ -0,0 +1,99 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+func TestReadSimilarity(t *testing.T) {
+	pairs, err := ReadSimilarity(strings.NewReader("Word 1,Word 2,Human (mean)\nlove,sex,6.77\n\n# comment\ntiger\tcat\t7.35\textra\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []SimilarityPair{
+		{Word1: "love", Word2: "sex", Score: 6.77},
+		{Word1: "tiger", Word2: "cat", Score: 7.35},
+	}, pairs)
+
+	_, err = ReadSimilarity(strings.NewReader("love sex 6.77\ntiger cat x\n"))
+	assert.Error(t, err)
+	_, err = ReadSimilarity(strings.NewReader("love sex\n"))
+	assert.Error(t, err)
+}
+
+func TestReadAnalogy(t *testing.T) {
+	questions, err := ReadAnalogy(strings.NewReader(": capital-common-countries\nAthens Greece Baghdad Iraq\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []AnalogyQuestion{{A: "Athens", B: "Greece", C: "Baghdad", D: "Iraq"}}, questions)
+
+	_, err = ReadAnalogy(strings.NewReader("Athens Greece Baghdad\n"))
+	assert.Error(t, err)
+}
+
+func TestSpearman(t *testing.T) {
+	testCases := []struct {
+		name     string
+		x, y     []float64
+		expected float64
+	}{
+		{name: "monotonic", x: []float64{1, 2, 3, 4}, y: []float64{10, 20, 30, 1000}, expected: 1},
+		{name: "reversed", x: []float64{1, 2, 3}, y: []float64{3, 2, 1}, expected: -1},
+		{name: "ties", x: []float64{1, 2, 2, 3}, y: []float64{1, 2, 3, 4}, expected: 0.9486832980505138},
+		{name: "constant", x: []float64{1, 1, 1}, y: []float64{1, 2, 3}, expected: 0},
+		{name: "too short", x: []float64{1}, y: []float64{1}, expected: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.InDelta(t, tc.expected, Spearman(tc.x, tc.y), 1e-9)
+		})
+	}
+}
+
+func newEmbedding(word string, vec ...float64) embedding.Embedding {
+	return embedding.Embedding{Word: word, Dim: len(vec), Vector: vec}
+}
+
+func TestIndex(t *testing.T) {
+	idx := NewIndex(embedding.Embeddings{
+		newEmbedding("man", 1, 0, 0),
+		newEmbedding("woman", 1, 1, 0),
+		newEmbedding("king", 1, 0, 1),
+		newEmbedding("queen", 1, 1, 1),
+		newEmbedding("apple", -1, 0, 0),
+	})
+
+	sim := idx.Similarity([]SimilarityPair{
+		{Word1: "king", Word2: "queen", Score: 9},
+		{Word1: "man", Word2: "apple", Score: 1},
+		{Word1: "Man", Word2: "woman", Score: 8},
+		{Word1: "man", Word2: "unknown", Score: 5},
+	})
+	assert.Equal(t, 1.0, sim.Score)
+	assert.Equal(t, 3, sim.Found)
+	assert.Equal(t, 0.75, sim.Coverage())
+
+	analogy := idx.Analogy([]AnalogyQuestion{
+		{A: "man", B: "woman", C: "king", D: "queen"},
+		{A: "man", B: "king", C: "woman", D: "apple"},
+		{A: "man", B: "woman", C: "king", D: "unknown"},
+	})
+	assert.Equal(t, 0.5, analogy.Score)
+	assert.Equal(t, 2, analogy.Found)
+	assert.Equal(t, 3, analogy.Total)
+}
@@ -0,0 +1,269 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/model"
+)
+
+// Suffix is appended to the path of the output vectors to name their metadata file.
+const Suffix = ".meta.json"
+
+// Metadata describes the run which produced the word vectors.
+type Metadata struct {
+	Model          string                 `json:"model"`
+	Options        map[string]interface{} `json:"options"`
+	Version        string                 `json:"version"`
+	CreatedAt      time.Time              `json:"created_at"`
+	Inputs         []File                 `json:"inputs"`
+	Output         string                 `json:"output"`
+	VectorType     string                 `json:"vector_type"`
+	Format         string                 `json:"format,omitempty"`
+	Vocab          int                    `json:"vocab"`
+	FilteredVocab  int                    `json:"filtered_vocab"`
+	Tokens         int                    `json:"tokens"`
+	FilteredTokens int                    `json:"filtered_tokens"`
+	EpochSeconds   []float64              `json:"epoch_seconds"`
+	TrainSeconds   float64                `json:"train_seconds"`
+	FinalLoss      *float64               `json:"final_loss,omitempty"`
+	Seed           *int64                 `json:"seed,omitempty"`
+	Stopped        bool                   `json:"stopped"`
+}
+
+// File is an input file of the run.
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Path returns the path of the metadata file for the vectors saved in output.
+func Path(output string) string {
+	return output + Suffix
+}
+
+// New returns the metadata of the model named name, trained with opts.
+// opts is a struct of options, e.g. word2vec.Options, and it is recorded field by field.
+func New(name string, opts interface{}) *Metadata {
+	m := &Metadata{
+		Model:     name,
+		Options:   Options(opts),
+		Version:   Version(),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	if seed, ok := m.Options["Seed"].(int64); ok {
+		m.Seed = &seed
+	}
+	return m
+}
+
+// Options returns the exported fields of the struct opts keyed by their names.
+// time.Duration is written in its string form, e.g. "1m30s".
+func Options(opts interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+	v := reflect.Indirect(reflect.ValueOf(opts))
+	if v.Kind() != reflect.Struct {
+		return res
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		switch val := v.Field(i).Interface().(type) {
+		case time.Duration:
+			res[f.Name] = val.String()
+		default:
+			res[f.Name] = val
+		}
+	}
+	return res
+}
+
+// AddInput records the size and the checksum of the file at path.
+func (m *Metadata) AddInput(path string) error {
+	f, err := NewFile(path)
+	if err != nil {
+		return err
+	}
+	m.Inputs = append(m.Inputs, f)
+	return nil
+}
+
+// NewFile returns the size and the checksum of the file at path.
+func NewFile(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return File{}, errors.Wrapf(err, "failed to read %s", path)
+	}
+	return File{
+		Path:   path,
+		Size:   size,
+		SHA256: hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// SetStats records the statistics reported by the model.
+func (m *Metadata) SetStats(stats model.Stats) {
+	m.Vocab = stats.Vocab
+	m.FilteredVocab = stats.FilteredVocab
+	m.Tokens = stats.Tokens
+	m.FilteredTokens = stats.FilteredTokens
+	m.EpochSeconds = make([]float64, len(stats.EpochTimes))
+	for i, d := range stats.EpochTimes {
+		m.EpochSeconds[i] = d.Seconds()
+	}
+}
+
+// SetLoss records the last value of history as the final loss.
+// It is left empty if no epoch is finished or the loss is not a finite number.
+func (m *Metadata) SetLoss(history []float64) {
+	if len(history) == 0 {
+		return
+	}
+	last := history[len(history)-1]
+	if math.IsNaN(last) || math.IsInf(last, 0) {
+		return
+	}
+	m.FinalLoss = &last
+}
+
+// Write writes m into w as indented JSON.
+func (m *Metadata) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// Save writes m into the file at path.
+func (m *Metadata) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.Write(f); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to write metadata into %s", path)
+	}
+	return f.Close()
+}
+
+// Read reads the metadata from r.
+func Read(r io.Reader) (*Metadata, error) {
+	var m Metadata
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, errors.Wrap(err, "failed to decode metadata")
+	}
+	return &m, nil
+}
+
+// Load reads the metadata of the vectors saved in output. It returns nil without error
+// if the vectors have no metadata, e.g. they are not trained by wego.
+func Load(output string) (*Metadata, error) {
+	f, err := os.Open(Path(output))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := Read(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load %s", Path(output))
+	}
+	return m, nil
+}
+
+// Describe writes the human readable summary of m into w.
+func (m *Metadata) Describe(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "model: %s (%s, vector type %s", m.Model, m.Version, m.VectorType)
+	if m.Format != "" {
+		fmt.Fprintf(&b, ", format %s", m.Format)
+	}
+	b.WriteString(")\n")
+	fmt.Fprintf(&b, "created at: %s\n", m.CreatedAt.Format(time.RFC3339))
+	for _, in := range m.Inputs {
+		fmt.Fprintf(&b, "input: %s (%d bytes, sha256 %s)\n", in.Path, in.Size, in.SHA256)
+	}
+	fmt.Fprintf(&b, "vocab: %d (%d after filtering), tokens: %d (%d after filtering)\n",
+		m.Vocab, m.FilteredVocab, m.Tokens, m.FilteredTokens)
+	fmt.Fprintf(&b, "epochs: %d, train time: %v", len(m.EpochSeconds), seconds(m.TrainSeconds))
+	if m.FinalLoss != nil {
+		fmt.Fprintf(&b, ", final loss: %f", *m.FinalLoss)
+	}
+	if m.Seed != nil {
+		fmt.Fprintf(&b, ", seed: %d", *m.Seed)
+	}
+	if m.Stopped {
+		b.WriteString(", stopped before completion")
+	}
+	b.WriteString("\n")
+	keys := make([]string, 0, len(m.Options))
+	for k := range m.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	opts := make([]string, len(keys))
+	for i, k := range keys {
+		opts[i] = fmt.Sprintf("%s=%v", k, m.Options[k])
+	}
+	fmt.Fprintf(&b, "options: %s\n", strings.Join(opts, " "))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Millisecond)
+}
+
+// Version returns the version of the wego binary, with the vcs revision if it is known.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	version := info.Main.Version
+	if version == "" {
+		version = "(devel)"
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			return version + " " + s.Value
+		}
+	}
+	return version
+}
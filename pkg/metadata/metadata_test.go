@@ -0,0 +1,108 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+)
+
+type testOptions struct {
+	Dim        int
+	Seed       int64
+	TimeBudget time.Duration
+	hidden     bool
+}
+
+func TestNew(t *testing.T) {
+	m := New("test", testOptions{Dim: 10, Seed: 3, TimeBudget: time.Minute})
+	assert.Equal(t, "test", m.Model)
+	assert.Equal(t, map[string]interface{}{
+		"Dim":        10,
+		"Seed":       int64(3),
+		"TimeBudget": "1m0s",
+	}, m.Options)
+	if assert.NotNil(t, m.Seed) {
+		assert.Equal(t, int64(3), *m.Seed)
+	}
+	assert.NotEmpty(t, m.Version)
+}
+
+func TestSetLoss(t *testing.T) {
+	testCases := []struct {
+		name     string
+		history  []float64
+		expected *float64
+	}{
+		{name: "empty", history: nil},
+		{name: "last", history: []float64{2, 1}, expected: func() *float64 { v := 1.0; return &v }()},
+		{name: "nan", history: []float64{1, math.NaN()}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := &Metadata{}
+			m.SetLoss(tc.history)
+			assert.Equal(t, tc.expected, m.FinalLoss)
+		})
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	assert.NoError(t, os.WriteFile(input, []byte("a b c\n"), 0644))
+	output := filepath.Join(dir, "vectors.txt")
+
+	m := New("test", testOptions{Dim: 10, Seed: 3})
+	assert.NoError(t, m.AddInput(input))
+	m.SetStats(model.Stats{
+		Vocab:          3,
+		FilteredVocab:  2,
+		Tokens:         3,
+		FilteredTokens: 2,
+		EpochTimes:     []time.Duration{time.Second, 500 * time.Millisecond},
+	})
+	m.SetLoss([]float64{0.5})
+	assert.NoError(t, m.Save(Path(output)))
+
+	loaded, err := Load(output)
+	assert.NoError(t, err)
+	assert.Equal(t, []File{{
+		Path:   input,
+		Size:   6,
+		SHA256: "1a25953465ab671d54b30108a9951b5500fa40994098ef9463853004da7933e1",
+	}}, loaded.Inputs)
+	assert.Equal(t, []float64{1, 0.5}, loaded.EpochSeconds)
+	assert.Equal(t, 2, loaded.FilteredVocab)
+	assert.Equal(t, 0.5, *loaded.FinalLoss)
+	assert.Equal(t, int64(3), *loaded.Seed)
+
+	var buf bytes.Buffer
+	assert.NoError(t, loaded.Describe(&buf))
+	assert.Contains(t, buf.String(), "vocab: 3 (2 after filtering)")
+	assert.Contains(t, buf.String(), "Dim=10")
+
+	missing, err := Load(filepath.Join(dir, "missing.txt"))
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
@@ -19,29 +19,34 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
-	"sync"
-
-	"golang.org/x/sync/semaphore"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/ynqa/wego/pkg/corpus"
-	"github.com/ynqa/wego/pkg/corpus/fs"
-	"github.com/ynqa/wego/pkg/corpus/memory"
 	"github.com/ynqa/wego/pkg/model"
 	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/loss"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/progress"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/shuffle"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/num"
 	"github.com/ynqa/wego/pkg/util/verbose"
 )
 
-type glove struct {
+type glove[T num.Float] struct {
 	opts Options
 
 	corpus corpus.Corpus
 
-	param  *matrix.Matrix
-	solver solver
+	param      *matrix.MatrixOf[T]
+	solver     solver[T]
+	schedule   schedule.Schedule
+	progress   *progress.Progress
+	loss       *loss.Loss
+	epochTimes []time.Duration
 
 	verbose *verbose.Verbose
 }
@@ -56,147 +61,223 @@ func New(opts ...ModelOption) (model.Model, error) {
 }
 
 func NewForOptions(opts Options) (model.Model, error) {
-	// TODO: validate Options
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Precision == matrix.Float32 {
+		return newGlove[float32](opts), nil
+	}
+	return newGlove[float64](opts), nil
+}
+
+func newGlove[T num.Float](opts Options) *glove[T] {
 	v := verbose.New(opts.Verbose)
-	return &glove{
+	return &glove[T]{
 		opts: opts,
 
+		loss: loss.New(),
+
 		verbose: v,
-	}, nil
+	}
 }
 
-func (g *glove) Train(r io.ReadSeeker) error {
-	if g.opts.DocInMemory {
-		g.corpus = memory.New(r, g.opts.ToLower, g.opts.MaxCount, g.opts.MinCount)
-	} else {
-		g.corpus = fs.New(r, g.opts.ToLower, g.opts.MaxCount, g.opts.MinCount)
+func (g *glove[T]) Train(ctx context.Context, r io.ReadSeeker) error {
+	if g.opts.TimeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.opts.TimeBudget)
+		defer cancel()
 	}
 
-	if err := g.corpus.Load(
+	c, err := modelutil.LoadCorpus(
+		ctx, r,
+		corpus.Key{
+			InMemory: g.opts.DocInMemory,
+			ToLower:  g.opts.ToLower,
+			MaxCount: g.opts.MaxCount,
+			MinCount: g.opts.MinCount,
+		},
 		&corpus.WithCooccurrence{
 			CountType: g.opts.CountType,
 			Window:    g.opts.Window,
 		},
 		g.verbose, g.opts.LogBatch,
-	); err != nil {
+	)
+	if err != nil {
 		return err
 	}
+	g.corpus = c
 
 	dic, dim := g.corpus.Dictionary(), g.opts.Dim
 
+	rnd := rand.New(rand.NewSource(g.opts.Seed))
 	dimAndBias := dim + 1
 	g.param = matrix.New(
 		dic.Len()*2,
 		dimAndBias,
-		func(_ int, vec []float64) {
+		func(_ int, vec []T) {
 			for i := 0; i < dim+1; i++ {
-				vec[i] = rand.Float64() / float64(dim)
+				vec[i] = T(rnd.Float64() / float64(dim))
 			}
 		},
 	)
 
+	sched, err := schedule.New(g.opts.LRSchedule, schedule.Config{
+		Initlr:      g.opts.Initlr,
+		MinLR:       g.opts.MinLR,
+		Iter:        g.opts.Iter,
+		WarmupRatio: g.opts.WarmupRatio,
+		StepDecay:   g.opts.StepDecay,
+	})
+	if err != nil {
+		return err
+	}
+	g.schedule = sched
+
 	switch g.opts.SolverType {
 	case Stochastic:
-		g.solver = newStochastic(g.opts)
+		g.solver = newStochastic[T]()
 	case AdaGrad:
-		g.solver = newAdaGrad(dic, g.opts)
+		g.solver = newAdaGrad[T](dic, g.opts)
 	default:
 		return errors.Errorf("invalid solver: %s not in %s|%s", g.opts.SolverType, Stochastic, AdaGrad)
 	}
 
-	return g.train()
+	return g.train(ctx)
 }
 
-func (g *glove) train() error {
+func (g *glove[T]) train(ctx context.Context) error {
 	items := g.makeItems(g.corpus.Cooccurrence())
 	itemSize := len(items)
 	indexPerThread := modelutil.IndexPerThread(
 		g.opts.Goroutines,
 		itemSize,
 	)
+	g.progress = progress.New(itemSize*g.opts.Iter, g.opts.LogBatch)
 
-	for i := 0; i < g.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go g.observe(trained, clk)
+	if g.opts.Shuffle {
+		shuffle.Slice(shuffle.Rand(g.opts.Seed), items)
+	}
 
-		sem := semaphore.NewWeighted(int64(g.opts.Goroutines))
-		wg := &sync.WaitGroup{}
+	for i := 0; i < g.opts.Iter && ctx.Err() == nil; i++ {
+		clk := g.startEpoch()
 
-		for i := 0; i < g.opts.Goroutines; i++ {
-			wg.Add(1)
-			s, e := indexPerThread[i], indexPerThread[i+1]
-			go g.trainPerThread(items[s:e], trained, sem, wg)
+		if g.opts.Shuffle {
+			// the chunks are visited in random order and each of them is shuffled by its worker,
+			// so that the items are reordered every iteration without the global pass.
+			chunks := shuffle.Chunks(shuffle.Rand(g.opts.Seed, i), itemSize, g.opts.BatchSize)
+			modelutil.RunWorkers(g.opts.Goroutines, modelutil.Jobs(chunks), func(t int) func([2]int) {
+				rnd := shuffle.Rand(g.opts.Seed, i, t)
+				return func(chunk [2]int) {
+					g.trainPerThread(ctx, items[chunk[0]:chunk[1]], rnd)
+				}
+			})
+		} else {
+			modelutil.RunWorkers(g.opts.Goroutines, modelutil.Jobs(modelutil.Intervals(indexPerThread)), func(int) func([2]int) {
+				return func(chunk [2]int) {
+					g.trainPerThread(ctx, items[chunk[0]:chunk[1]], nil)
+				}
+			})
 		}
 
-		wg.Wait()
-		close(trained)
+		g.endEpoch(clk)
 	}
-	return nil
+	return ctx.Err()
 }
 
-func (g *glove) trainPerThread(
+// trainPerThread trains items in order after shuffling them by rnd if it's not nil.
+func (g *glove[T]) trainPerThread(
+	ctx context.Context,
 	items []item,
-	trained chan struct{},
-	sem *semaphore.Weighted,
-	wg *sync.WaitGroup,
-) error {
-	defer func() {
-		wg.Done()
-		sem.Release(1)
-	}()
-
-	if err := sem.Acquire(context.Background(), 1); err != nil {
-		return err
+	rnd *rand.Rand,
+) {
+	if rnd != nil {
+		shuffle.Slice(rnd, items)
 	}
 
+	var (
+		sum float64
+		cnt int
+	)
 	dic := g.corpus.Dictionary()
-	for _, item := range items {
-		g.solver.trainOne(item.l1, item.l2+dic.Len(), g.param, item.f, item.coef)
-		g.solver.trainOne(item.l1+dic.Len(), item.l2, g.param, item.f, item.coef)
-		trained <- struct{}{}
+	counter := g.progress.NewCounter(g.opts.UpdateLRBatch)
+	lr := g.schedule(g.progress.Ratio())
+	for i, item := range items {
+		if i%g.opts.BatchSize == 0 && ctx.Err() != nil {
+			break
+		}
+		sum += g.solver.trainOne(item.l1, item.l2+dic.Len(), g.param, item.f, item.coef, lr)
+		sum += g.solver.trainOne(item.l1+dic.Len(), item.l2, g.param, item.f, item.coef, lr)
+		cnt++
+		if counter.Inc() {
+			lr = g.schedule(g.progress.Ratio())
+		}
 	}
-
-	return nil
+	counter.Flush()
+	g.loss.Add(sum, cnt*2)
 }
 
-func (g *glove) observe(trained chan struct{}, clk *clock.Clock) {
-	var cnt int
-	for range trained {
+func (g *glove[T]) startEpoch() *clock.Clock {
+	clk := clock.New()
+	g.progress.StartEpoch(func(trained int64) {
 		g.verbose.Do(func() {
-			cnt++
-			if cnt%g.opts.LogBatch == 0 {
-				fmt.Printf("trained %d items %v\r", cnt, clk.AllElapsed())
-			}
+			fmt.Printf("trained %d items %v loss %f\r", trained, clk.AllElapsed(), g.loss.Current())
 		})
-	}
+	})
+	return clk
+}
+
+func (g *glove[T]) endEpoch(clk *clock.Clock) {
+	g.loss.Epoch()
+	g.epochTimes = append(g.epochTimes, clk.AllElapsed())
 	g.verbose.Do(func() {
-		fmt.Printf("trained %d items %v\r\n", cnt, clk.AllElapsed())
+		fmt.Printf("trained %d items %v loss %f\r\n", g.progress.Epoch(), clk.AllElapsed(), g.loss.Last())
 	})
 }
 
-func (g *glove) Save(f io.Writer, typ vector.Type) error {
-	return vector.Save(f, g.corpus.Dictionary(), g.WordVector(typ), g.verbose, g.opts.LogBatch)
+func (g *glove[T]) Save(f io.Writer, typ vector.Type) error {
+	return g.SaveFormat(f, typ, vector.Text)
 }
 
-func (g *glove) WordVector(typ vector.Type) *matrix.Matrix {
-	var mat *matrix.Matrix
-	dic := g.corpus.Dictionary()
-	if typ == vector.Agg {
-		mat = matrix.New(dic.Len(), g.opts.Dim,
-			func(row int, vec []float64) {
-				for i := 0; i < g.opts.Dim; i++ {
-					vec[i] = g.param.Slice(row)[i]
-				}
-			},
-		)
-	} else {
-		mat = matrix.New(dic.Len(), g.opts.Dim,
-			func(row int, vec []float64) {
-				for i := 0; i < g.opts.Dim; i++ {
-					vec[i] = g.param.Slice(row)[i] + g.param.Slice(row + dic.Len())[i]
-				}
-			},
-		)
+func (g *glove[T]) SaveFormat(f io.Writer, typ vector.Type, format vector.Format) error {
+	mat, err := g.wordVector(typ)
+	if err != nil {
+		return err
+	}
+	return vector.Save(f, g.corpus.Dictionary(), mat, format, g.verbose, g.opts.LogBatch)
+}
+
+func (g *glove[T]) Loss() []float64 {
+	return g.loss.History()
+}
+
+func (g *glove[T]) Stats() model.Stats {
+	return modelutil.CorpusStats(g.corpus, g.opts.MaxCount, g.opts.MinCount, g.epochTimes)
+}
+
+func (g *glove[T]) WordVector(typ vector.Type) (*matrix.Matrix, error) {
+	mat, err := g.wordVector(typ)
+	if err != nil {
+		return nil, err
 	}
-	return mat
+	return matrix.Convert[float64](mat), nil
+}
+
+// wordVector uses the first Dim elements of the parameters, or Dim+1 elements with the bias term if Bias.
+func (g *glove[T]) wordVector(typ vector.Type) (*matrix.MatrixOf[T], error) {
+	if g.param == nil {
+		return nil, model.ErrNotTrained
+	}
+	n, dim := g.corpus.Dictionary().Len(), g.opts.Dim
+	if g.opts.Bias {
+		dim++
+	}
+	return vector.Compose(typ, n,
+		func(row int) []T {
+			return g.param.Slice(row)[:dim]
+		},
+		func(row int) []T {
+			return g.param.Slice(row + n)[:dim]
+		},
+		"",
+	)
 }
@@ -0,0 +1,107 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package glove
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/internal/modeltest"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/num"
+)
+
+func TestTrain(t *testing.T) {
+	doc := modeltest.Corpus(20000, 500)
+	for _, tc := range []struct {
+		inMemory bool
+		shuffle  bool
+	}{
+		{inMemory: true, shuffle: false},
+		{inMemory: true, shuffle: true},
+		{inMemory: false, shuffle: false},
+		{inMemory: false, shuffle: true},
+	} {
+		t.Run(fmt.Sprintf("inMemory=%t,shuffle=%t", tc.inMemory, tc.shuffle), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.DocInMemory = tc.inMemory
+			opts.Shuffle = tc.shuffle
+			opts.Goroutines = 1
+			opts.Iter = 2
+			opts.UpdateLRBatch = 100
+			m, err := NewForOptions(opts)
+			assert.NoError(t, err)
+			modeltest.Train(t, m, doc, opts.Iter, opts.Dim)
+		})
+	}
+}
+
+func TestWordVectorBias(t *testing.T) {
+	doc := modeltest.Corpus(5000, 100)
+	opts := DefaultOptions()
+	opts.Bias = true
+	opts.Goroutines = 1
+	opts.Iter = 1
+	m, err := NewForOptions(opts)
+	assert.NoError(t, err)
+	assert.NoError(t, m.Train(context.Background(), bytes.NewReader(doc)))
+	for typ, dim := range map[vector.Type]int{
+		vector.Word:    opts.Dim + 1,
+		vector.Context: opts.Dim + 1,
+		vector.Sum:     opts.Dim + 1,
+		vector.Concat:  2 * (opts.Dim + 1),
+	} {
+		vec, err := m.WordVector(typ)
+		assert.NoError(t, err)
+		assert.Equal(t, dim, vec.Col(), typ)
+	}
+}
+
+func benchmarkTrain[T num.Float](b *testing.B, precision matrix.Precision, goroutines int) {
+	opts := DefaultOptions()
+	opts.Precision = precision
+	opts.DocInMemory = true
+	opts.Dim = 100
+	opts.Goroutines = goroutines
+	opts.Iter = 1
+	doc := modeltest.Corpus(modeltest.BenchWords, 5000)
+
+	modeltest.Benchmark(b, func() (*matrix.MatrixOf[T], error) {
+		m := newGlove[T](opts)
+		err := m.Train(context.Background(), bytes.NewReader(doc))
+		return m.param, err
+	})
+}
+
+func BenchmarkTrainFloat32(b *testing.B) {
+	benchmarkTrain[float32](b, matrix.Float32, 1)
+}
+
+func BenchmarkTrainFloat64(b *testing.B) {
+	benchmarkTrain[float64](b, matrix.Float64, 1)
+}
+
+func BenchmarkTrainGoroutines(b *testing.B) {
+	for _, n := range modeltest.BenchGoroutines {
+		b.Run(fmt.Sprintf("goroutines=%d", n), func(b *testing.B) {
+			benchmarkTrain[float32](b, matrix.Float32, n)
+		})
+	}
+}
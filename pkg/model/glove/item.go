@@ -17,6 +17,7 @@ package glove
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
 	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
@@ -29,7 +30,7 @@ type item struct {
 	coef   float64
 }
 
-func (g *glove) makeItems(cooc *co.Cooccurrence) []item {
+func (g *glove[T]) makeItems(cooc *co.Cooccurrence) []item {
 	em := cooc.EncodedMatrix()
 	res, idx, clk := make([]item, len(em)), 0, clock.New()
 	for enc, f := range em {
@@ -55,5 +56,12 @@ func (g *glove) makeItems(cooc *co.Cooccurrence) []item {
 	g.verbose.Do(func() {
 		fmt.Printf("build %d items %v\r\n", idx, clk.AllElapsed())
 	})
+	// fix the order of map iteration, so that the shuffled order is reproducible by seed.
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].l1 != res[j].l1 {
+			return res[i].l1 < res[j].l1
+		}
+		return res[i].l2 < res[j].l2
+	})
 	return res
 }
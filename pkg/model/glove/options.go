@@ -16,9 +16,13 @@ package glove
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/spf13/cobra"
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/validate"
 )
 
 type SolverType = string
@@ -31,19 +35,29 @@ const (
 var (
 	defaultAlpha              = 0.75
 	defaultBatchSize          = 10000
+	defaultBias               = false
 	defaultCountType          = co.Increment
 	defaultDim                = 10
 	defaultDocInMemory        = false
 	defaultGoroutines         = runtime.NumCPU()
 	defaultInitlr             = 0.025
 	defaultIter               = 15
+	defaultLRSchedule         = schedule.Constant
 	defaultLogBatch           = 100000
 	defaultMaxCount           = -1
 	defaultMinCount           = 5
+	defaultMinLR              = defaultInitlr * 1.0e-4
+	defaultPrecision          = matrix.Float64
+	defaultSeed               = int64(1)
+	defaultShuffle            = true
 	defaultSolverType         = Stochastic
+	defaultStepDecay          = 0.5
 	defaultSubsampleThreshold = 1.0e-3
+	defaultTimeBudget         = time.Duration(0)
 	defaultToLower            = false
+	defaultUpdateLRBatch      = 100000
 	defaultVerbose            = false
+	defaultWarmupRatio        = 0.1
 	defaultWindow             = 5
 	defaultXmax               = 100
 )
@@ -51,19 +65,29 @@ var (
 type Options struct {
 	Alpha              float64
 	BatchSize          int
+	Bias               bool
 	CountType          co.CountType
 	Dim                int
 	DocInMemory        bool
 	Goroutines         int
 	Initlr             float64
 	Iter               int
+	LRSchedule         schedule.Type
 	LogBatch           int
 	MaxCount           int
 	MinCount           int
+	MinLR              float64
+	Precision          matrix.Precision
+	Seed               int64
+	Shuffle            bool
 	SolverType         SolverType
+	StepDecay          float64
 	SubsampleThreshold float64
+	TimeBudget         time.Duration
 	ToLower            bool
+	UpdateLRBatch      int
 	Verbose            bool
+	WarmupRatio        float64
 	Window             int
 	Xmax               int
 }
@@ -72,42 +96,88 @@ func DefaultOptions() Options {
 	return Options{
 		Alpha:              defaultAlpha,
 		BatchSize:          defaultBatchSize,
+		Bias:               defaultBias,
 		CountType:          defaultCountType,
 		Dim:                defaultDim,
 		DocInMemory:        defaultDocInMemory,
 		Goroutines:         defaultGoroutines,
 		Initlr:             defaultInitlr,
 		Iter:               defaultIter,
+		LRSchedule:         defaultLRSchedule,
 		LogBatch:           defaultLogBatch,
 		MaxCount:           defaultMaxCount,
 		MinCount:           defaultMinCount,
+		MinLR:              defaultMinLR,
+		Precision:          defaultPrecision,
+		Seed:               defaultSeed,
+		Shuffle:            defaultShuffle,
 		SolverType:         defaultSolverType,
+		StepDecay:          defaultStepDecay,
 		SubsampleThreshold: defaultSubsampleThreshold,
+		TimeBudget:         defaultTimeBudget,
 		ToLower:            defaultToLower,
+		UpdateLRBatch:      defaultUpdateLRBatch,
 		Verbose:            defaultVerbose,
+		WarmupRatio:        defaultWarmupRatio,
 		Window:             defaultWindow,
 		Xmax:               defaultXmax,
 	}
 }
 
+// Validate reports all invalid values of Options at once.
+func (opts Options) Validate() error {
+	v := validate.New()
+	v.NonNegativeFloat("Alpha", opts.Alpha)
+	v.Positive("BatchSize", opts.BatchSize)
+	v.OneOf("CountType", opts.CountType, co.Increment, co.Proximity)
+	v.Positive("Dim", opts.Dim)
+	v.Positive("Goroutines", opts.Goroutines)
+	v.PositiveFloat("Initlr", opts.Initlr)
+	v.Positive("Iter", opts.Iter)
+	v.OneOf("LRSchedule", opts.LRSchedule, schedule.Linear, schedule.Constant, schedule.Cosine, schedule.Step, schedule.Warmup)
+	v.Positive("LogBatch", opts.LogBatch)
+	v.NonNegativeFloat("MinLR", opts.MinLR)
+	v.Check(opts.MinLR <= opts.Initlr, "MinLR", opts.MinLR, fmt.Sprintf("must be <= Initlr=%v", opts.Initlr))
+	v.OneOf("Precision", opts.Precision, matrix.Float64, matrix.Float32)
+	v.OneOf("SolverType", opts.SolverType, Stochastic, AdaGrad)
+	v.Range("StepDecay", opts.StepDecay, 0, 1)
+	v.NonNegativeFloat("SubsampleThreshold", opts.SubsampleThreshold)
+	v.Check(opts.TimeBudget >= 0, "TimeBudget", opts.TimeBudget, "must be >= 0")
+	v.Positive("UpdateLRBatch", opts.UpdateLRBatch)
+	v.Check(0 <= opts.WarmupRatio && opts.WarmupRatio < 1, "WarmupRatio", opts.WarmupRatio, "must be in [0, 1)")
+	v.Positive("Window", opts.Window)
+	v.Positive("Xmax", opts.Xmax)
+	return v.Err()
+}
+
 func LoadForCmd(cmd *cobra.Command, opts *Options) {
-	cmd.Flags().Float64Var(&opts.Alpha, "alpha", defaultAlpha, "exponent of weighting function")
-	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train")
+	cmd.Flags().Float64Var(&opts.Alpha, "alpha", defaultAlpha, "exponent of weighting function (>= 0)")
+	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train (> 0)")
+	cmd.Flags().BoolVar(&opts.Bias, "bias", defaultBias, "whether to append the bias term to the word and context vectors")
 	cmd.Flags().StringVar(&opts.CountType, "cnt", defaultCountType, fmt.Sprintf("count type for co-occurrence words. One of %s|%s", co.Increment, co.Proximity))
-	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector")
-	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine")
+	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector (> 0)")
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine (> 0)")
 	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", defaultDocInMemory, "whether to store the doc in memory")
-	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate")
-	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration")
-	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words")
+	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate (> 0)")
+	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration (> 0)")
+	cmd.Flags().StringVar(&opts.LRSchedule, "lr-schedule", defaultLRSchedule, fmt.Sprintf("learning rate schedule over total training progress. One of: %s|%s|%s|%s|%s", schedule.Linear, schedule.Constant, schedule.Cosine, schedule.Step, schedule.Warmup))
+	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words (> 0)")
 	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words")
 	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words")
+	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", defaultMinLR, "lower limit of learning rate (in [0, initlr])")
+	cmd.Flags().StringVar(&opts.Precision, "precision", defaultPrecision, fmt.Sprintf("floating point type to store parameters. One of: %s|%s", matrix.Float64, matrix.Float32))
+	cmd.Flags().Int64Var(&opts.Seed, "seed", defaultSeed, "random seed for initialization and shuffling")
+	cmd.Flags().BoolVar(&opts.Shuffle, "shuffle", defaultShuffle, "whether to shuffle the co-occurrence items every iteration")
 	cmd.Flags().StringVar(&opts.SolverType, "solver", defaultSolverType, fmt.Sprintf("solver for GloVe objective. One of: %s|%s", Stochastic, AdaGrad))
-	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling")
+	cmd.Flags().Float64Var(&opts.StepDecay, "step-decay", defaultStepDecay, "factor to multiply learning rate at each epoch (for step schedule only; in [0, 1])")
+	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling (>= 0)")
+	cmd.Flags().DurationVar(&opts.TimeBudget, "time-budget", defaultTimeBudget, "wall-clock budget for training, e.g. 30m (no limit if zero; >= 0)")
 	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on corpus convert to lowercase or not")
+	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", defaultUpdateLRBatch, "batch size to update learning rate (> 0)")
 	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
-	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size")
-	cmd.Flags().IntVar(&opts.Xmax, "xmax", defaultXmax, "specifying cutoff in weighting function")
+	cmd.Flags().Float64Var(&opts.WarmupRatio, "warmup-ratio", defaultWarmupRatio, "ratio of training to increase learning rate linearly (for warmup schedule only; in [0, 1))")
+	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size (> 0)")
+	cmd.Flags().IntVar(&opts.Xmax, "xmax", defaultXmax, "specifying cutoff in weighting function (> 0)")
 }
 
 type ModelOption func(*Options)
@@ -124,6 +194,12 @@ func BatchSize(v int) ModelOption {
 	})
 }
 
+func Bias() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Bias = true
+	})
+}
+
 func DocInMemory() ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.DocInMemory = true
@@ -154,6 +230,12 @@ func Iter(v int) ModelOption {
 	})
 }
 
+func LRSchedule(v schedule.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRSchedule = v
+	})
+}
+
 func MaxCount(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.MaxCount = v
@@ -166,30 +248,78 @@ func MinCount(v int) ModelOption {
 	})
 }
 
+func MinLR(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MinLR = v
+	})
+}
+
+func Precision(typ matrix.Precision) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Precision = typ
+	})
+}
+
+func Seed(v int64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Seed = v
+	})
+}
+
+func Shuffle(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Shuffle = v
+	})
+}
+
 func Solver(typ SolverType) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.SolverType = typ
 	})
 }
 
+func StepDecay(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.StepDecay = v
+	})
+}
+
 func SubsampleThreshold(v float64) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.SubsampleThreshold = v
 	})
 }
 
+func TimeBudget(v time.Duration) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.TimeBudget = v
+	})
+}
+
 func ToLower() ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.ToLower = true
 	})
 }
 
+func UpdateLRBatch(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.UpdateLRBatch = v
+	})
+}
+
 func Verbose() ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Verbose = true
 	})
 }
 
+func WarmupRatio(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.WarmupRatio = v
+	})
+}
+
 func Window(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Window = v
@@ -12,32 +12,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package node
+package glove
 
-type Node struct {
-	cache  []*Node
-	Parent *Node
-	Val    int
+import (
+	"github.com/ynqa/wego/pkg/model"
+)
 
-	Code   int
-	Vector []float64
+// Registration is the entry of glove in the model registry.
+var Registration = model.Registration{
+	Name:  "glove",
+	Short: "GloVe: Global Vectors for Word Representation",
+	Factory: func() model.Factory {
+		return model.NewFactory(DefaultOptions, LoadForCmd, NewForOptions)
+	},
 }
 
-func (n *Node) GetPath(depth int) []*Node {
-	if n.cache == nil {
-		re := func(nodes []*Node) {
-			for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
-				nodes[i], nodes[j] = nodes[j], nodes[i]
-			}
-		}
-		n.cache = make([]*Node, 0)
-		for p := n; p != nil; p = p.Parent {
-			n.cache = append(n.cache, p)
-		}
-		re(n.cache)
-	}
-	if depth > len(n.cache) {
-		depth = len(n.cache)
-	}
-	return n.cache[:depth]
+func init() {
+	model.Register(Registration)
 }
@@ -19,52 +19,44 @@ import (
 
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
 )
 
-type solver interface {
-	trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64)
+type solver[T num.Float] interface {
+	trainOne(l1, l2 int, param *matrix.MatrixOf[T], f, coef, lr float64) float64
 }
 
-type stochastic struct {
-	initlr float64
-}
+type stochastic[T num.Float] struct{}
 
-func newStochastic(opts Options) solver {
-	return &stochastic{
-		initlr: opts.Initlr,
-	}
+func newStochastic[T num.Float]() solver[T] {
+	return &stochastic[T]{}
 }
 
-func (sol *stochastic) trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64) {
+func (sol *stochastic[T]) trainOne(l1, l2 int, param *matrix.MatrixOf[T], f, coef, lr float64) float64 {
 	v1, v2 := param.Slice(l1), param.Slice(l2)
-	dim, diff := len(v1)-1, 0.
-	for i := 0; i < dim; i++ {
-		diff += v1[i] * v2[i]
-	}
-	diff += v1[dim] + v2[dim] - f
-	diff *= coef * sol.initlr
-	for i := 0; i < dim; i++ {
-		t1, t2 := diff*v2[i], diff*v1[i]
-		v1[i] -= t1
-		v2[i] -= t2
-	}
-	v1[dim] -= diff
-	v2[dim] -= diff
+	dim := len(v1) - 1
+	diff := float64(vecmath.Dot(v1[:dim], v2[:dim])+v1[dim]+v2[dim]) - f
+	cost := 0.5 * coef * diff * diff
+	diff *= coef * lr
+	d := T(diff)
+	vecmath.CrossAxpy(-d, v1[:dim], v2[:dim])
+	v1[dim] -= d
+	v2[dim] -= d
+	return cost
 }
 
-type adaGrad struct {
-	initlr float64
-	gradsq *matrix.Matrix
+type adaGrad[T num.Float] struct {
+	gradsq *matrix.MatrixOf[T]
 }
 
-func newAdaGrad(dic *dictionary.Dictionary, opts Options) solver {
+func newAdaGrad[T num.Float](dic *dictionary.Dictionary, opts Options) solver[T] {
 	dimAndBias := opts.Dim + 1
-	return &adaGrad{
-		initlr: opts.Initlr,
+	return &adaGrad[T]{
 		gradsq: matrix.New(
 			dic.Len()*2,
 			dimAndBias,
-			func(_ int, vec []float64) {
+			func(_ int, vec []T) {
 				for i := 0; i < dimAndBias; i++ {
 					vec[i] = 1.
 				}
@@ -73,27 +65,27 @@ func newAdaGrad(dic *dictionary.Dictionary, opts Options) solver {
 	}
 }
 
-func (sol *adaGrad) trainOne(l1, l2 int, param *matrix.Matrix, f, coef float64) {
+func (sol *adaGrad[T]) trainOne(l1, l2 int, param *matrix.MatrixOf[T], f, coef, lr float64) float64 {
 	v1, v2 := param.Slice(l1), param.Slice(l2)
 	g1, g2 := sol.gradsq.Slice(l1), sol.gradsq.Slice(l2)
-	dim, diff := len(v1)-1, 0.
-	for i := 0; i < dim; i++ {
-		diff += v1[i] * v2[i]
-	}
-	diff += v1[dim] + v2[dim] - f
-	diff *= coef * sol.initlr
+	dim := len(v1) - 1
+	diff := float64(vecmath.Dot(v1[:dim], v2[:dim])+v1[dim]+v2[dim]) - f
+	cost := 0.5 * coef * diff * diff
+	diff *= coef * lr
+	d := T(diff)
 	for i := 0; i < dim; i++ {
-		t1, t2 := diff*v2[i], diff*v1[i]
+		t1, t2 := d*v2[i], d*v1[i]
 		g1[i] += t1 * t1
 		g2[i] += t2 * t2
-		t1 /= math.Sqrt(g1[i])
-		t2 /= math.Sqrt(g2[i])
+		t1 /= T(math.Sqrt(float64(g1[i])))
+		t2 /= T(math.Sqrt(float64(g2[i])))
 		v1[i] -= t1
 		v2[i] -= t2
 	}
-	v1[dim] -= diff / math.Sqrt(g1[dim])
-	v2[dim] -= diff / math.Sqrt(g2[dim])
-	diff *= diff
-	g1[dim] += diff
-	g2[dim] += diff
+	v1[dim] -= d / T(math.Sqrt(float64(g1[dim])))
+	v2[dim] -= d / T(math.Sqrt(float64(g2[dim])))
+	d *= d
+	g1[dim] += d
+	g2[dim] += d
+	return cost
 }
@@ -0,0 +1,84 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modeltest provides the helpers shared by the tests and the benchmarks of the models.
+//
+// The tests train the models with a single worker, so that `go test -race` reports the races
+// around the workers (progress, learning rate and loss tracking). The updates of the parameters
+// between the workers are lock-free by design (Hogwild), which the race detector would report.
+// word2vec's TestTrainWorkers runs two workers on the disjoint vocabularies instead.
+package modeltest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/num"
+)
+
+// BenchWords is the number of the words on the corpus of the benchmarks.
+const BenchWords = 200000
+
+// BenchGoroutines are the numbers of the workers which BenchmarkTrainGoroutines of the models
+// compares, e.g. on a machine with 16 or more CPUs.
+var BenchGoroutines = []int{1, 16, 32}
+
+// Corpus returns the words w0, w1, ... of vocab drawn from the Zipf distribution, which is
+// the same for the same arguments.
+func Corpus(words, vocab int) []byte {
+	var buf bytes.Buffer
+	zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, uint64(vocab-1))
+	for i := 0; i < words; i++ {
+		fmt.Fprintf(&buf, "w%d ", zipf.Uint64())
+	}
+	return buf.Bytes()
+}
+
+// Train trains m on doc, and checks that it has the loss of iter epochs and the word vectors
+// of dim. It returns the loss.
+func Train(t *testing.T, m model.Model, doc []byte, iter, dim int) []float64 {
+	assert.NoError(t, m.Train(context.Background(), bytes.NewReader(doc)))
+	loss := m.Loss()
+	assert.Len(t, loss, iter)
+	vec, err := m.WordVector(vector.Word)
+	assert.NoError(t, err)
+	assert.Equal(t, dim, vec.Col())
+	return loss
+}
+
+// Benchmark runs train b.N times, which trains a new model on the corpus of BenchWords words
+// and returns its parameters, and reports the throughput and the size of the parameters.
+func Benchmark[T num.Float](b *testing.B, train func() (*matrix.MatrixOf[T], error)) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	var param *matrix.MatrixOf[T]
+	for i := 0; i < b.N; i++ {
+		var err error
+		if param, err = train(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(BenchWords*b.N)/b.Elapsed().Seconds(), "words/s")
+	var zero T
+	b.ReportMetric(float64(param.Row()*param.Col())*float64(unsafe.Sizeof(zero)), "param-bytes")
+}
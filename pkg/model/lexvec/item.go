@@ -17,29 +17,115 @@ package lexvec
 import (
 	"fmt"
 	"math"
+	"sort"
 
 	"github.com/pkg/errors"
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
 	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/model/modelutil/pmi"
 	"github.com/ynqa/wego/pkg/util/clock"
 )
 
-func (l *lexvec) makeItems(cooc *co.Cooccurrence) (map[uint64]float64, error) {
-	em := cooc.EncodedMatrix()
-	res, idx, clk := make(map[uint64]float64), 0, clock.New()
-	logTotalFreq := math.Log(math.Pow(float64(l.corpus.Len()), l.opts.Smooth))
-	for enc, f := range em {
+// items stores the relation values of the co-occurring pairs in both orders as the
+// compressed sparse rows: the columns of the row l1 are cols[rows[l1]:rows[l1+1]] in
+// ascending order, and the value of (l1, l2) is looked up by binary search on them.
+type items struct {
+	rows []int
+	cols []int32
+	vals []float64
+}
+
+func (it *items) len() int {
+	return len(it.cols)
+}
+
+// at returns the value of (l1, l2), or 0 if they don't co-occur.
+func (it *items) at(l1, l2 int) float64 {
+	lo, hi := it.rows[l1], it.rows[l1+1]
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		if int(it.cols[m]) < l2 {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	if lo < it.rows[l1+1] && int(it.cols[lo]) == l2 {
+		return it.vals[lo]
+	}
+	return 0
+}
+
+// cellRows returns the row of each cell.
+func (it *items) cellRows() []int32 {
+	res := make([]int32, it.len())
+	for r := 0; r+1 < len(it.rows); r++ {
+		for i := it.rows[r]; i < it.rows[r+1]; i++ {
+			res[i] = int32(r)
+		}
+	}
+	return res
+}
+
+// rowSorter sorts the cells in a row by the columns.
+type rowSorter struct {
+	cols []int32
+	vals []float64
+}
+
+func (r rowSorter) Len() int           { return len(r.cols) }
+func (r rowSorter) Less(i, j int) bool { return r.cols[i] < r.cols[j] }
+func (r rowSorter) Swap(i, j int) {
+	r.cols[i], r.cols[j] = r.cols[j], r.cols[i]
+	r.vals[i], r.vals[j] = r.vals[j], r.vals[i]
+}
+
+func (l *lexvec[T]) makeItems(cooc *co.Cooccurrence) (*items, error) {
+	em, n := cooc.EncodedMatrix(), l.corpus.Dictionary().Len()
+	res := &items{
+		rows: make([]int, n+1),
+	}
+	// the co-occurrence matrix stores each pair once, so that it's expanded to both orders.
+	for enc := range em {
 		u1, u2 := encode.DecodeBigram(enc)
-		l1, l2 := int(u1), int(u2)
+		res.rows[u1+1]++
+		if u1 != u2 {
+			res.rows[u2+1]++
+		}
+	}
+	for i := 0; i < n; i++ {
+		res.rows[i+1] += res.rows[i]
+	}
+	res.cols, res.vals = make([]int32, res.rows[n]), make([]float64, res.rows[n])
+
+	next := make([]int, n)
+	copy(next, res.rows[:n])
+	idx, clk := 0, clock.New()
+	p := pmi.New(l.corpus.Dictionary(), l.corpus.Len(), l.opts.Smooth)
+	put := func(l1, l2 int, f float64) error {
 		v, err := l.calculateRelation(
 			l.opts.RelationType,
 			l1, l2,
-			f, logTotalFreq,
+			f, p,
 		)
 		if err != nil {
+			return err
+		}
+		res.cols[next[l1]], res.vals[next[l1]] = int32(l2), v
+		next[l1]++
+		return nil
+	}
+	for enc, f := range em {
+		u1, u2 := encode.DecodeBigram(enc)
+		l1, l2 := int(u1), int(u2)
+		if err := put(l1, l2, f); err != nil {
 			return nil, err
 		}
-		res[enc] = v
+		if l1 != l2 {
+			if err := put(l2, l1, f); err != nil {
+				return nil, err
+			}
+		}
 		idx++
 		l.verbose.Do(func() {
 			if idx%l.opts.LogBatch == 0 {
@@ -47,35 +133,32 @@ func (l *lexvec) makeItems(cooc *co.Cooccurrence) (map[uint64]float64, error) {
 			}
 		})
 	}
+	for i := 0; i < n; i++ {
+		sort.Sort(rowSorter{
+			cols: res.cols[res.rows[i]:res.rows[i+1]],
+			vals: res.vals[res.rows[i]:res.rows[i+1]],
+		})
+	}
 	l.verbose.Do(func() {
 		fmt.Printf("build %d items %v\r\n", idx, clk.AllElapsed())
 	})
 	return res, nil
 }
 
-func (l *lexvec) calculateRelation(
+func (l *lexvec[T]) calculateRelation(
 	typ RelationType,
 	l1, l2 int,
-	co, logTotalFreq float64,
+	co float64,
+	p *pmi.PMI,
 ) (float64, error) {
-	dic := l.corpus.Dictionary()
 	switch typ {
 	case PPMI:
-		if co == 0 {
-			return 0, nil
-		}
-		// TODO: avoid log for l1, l2 every time
-		ppmi := math.Log(co) - math.Log(float64(dic.IDFreq(l1))) - math.Log(math.Pow(float64(dic.IDFreq(l2)), l.opts.Smooth)) + logTotalFreq
-		if ppmi < 0 {
-			ppmi = 0
-		}
-		return ppmi, nil
+		return p.Positive(l1, l2, co, 1), nil
 	case PMI:
 		if co == 0 {
 			return 1, nil
 		}
-		pmi := math.Log(co) - math.Log(float64(dic.IDFreq(l1))) - math.Log(math.Pow(float64(dic.IDFreq(l2)), l.opts.Smooth)) + logTotalFreq
-		return pmi, nil
+		return p.Value(l1, l2, co), nil
 	case Collocation:
 		return co, nil
 	case LogCollocation:
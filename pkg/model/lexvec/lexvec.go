@@ -19,32 +19,39 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
-	"sync"
-
-	"golang.org/x/sync/semaphore"
+	"time"
 
 	"github.com/ynqa/wego/pkg/corpus"
 	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
-	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
-	"github.com/ynqa/wego/pkg/corpus/fs"
-	"github.com/ynqa/wego/pkg/corpus/memory"
 	"github.com/ynqa/wego/pkg/model"
 	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/loss"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/progress"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/shuffle"
 	"github.com/ynqa/wego/pkg/model/modelutil/subsample"
+	"github.com/ynqa/wego/pkg/model/modelutil/unigram"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
 	"github.com/ynqa/wego/pkg/util/verbose"
 )
 
-type lexvec struct {
+type lexvec[T num.Float] struct {
 	opts Options
 
 	corpus corpus.Corpus
 
-	param      *matrix.Matrix
+	param      *matrix.MatrixOf[T]
+	items      *items
+	sampler    *unigram.Sampler
 	subsampler *subsample.Subsampler
-	currentlr  float64
+	schedule   schedule.Schedule
+	progress   *progress.Progress
+	loss       *loss.Loss
+	epochTimes []time.Duration
 
 	verbose *verbose.Verbose
 }
@@ -59,146 +66,181 @@ func New(opts ...ModelOption) (model.Model, error) {
 }
 
 func NewForOptions(opts Options) (model.Model, error) {
-	// TODO: validate Options
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Precision == matrix.Float32 {
+		return newLexvec[float32](opts), nil
+	}
+	return newLexvec[float64](opts), nil
+}
+
+func newLexvec[T num.Float](opts Options) *lexvec[T] {
 	v := verbose.New(opts.Verbose)
-	return &lexvec{
+	return &lexvec[T]{
 		opts: opts,
 
-		currentlr: opts.Initlr,
+		loss: loss.New(),
 
 		verbose: v,
-	}, nil
+	}
 }
 
-func (l *lexvec) Train(r io.ReadSeeker) error {
-	if l.opts.DocInMemory {
-		l.corpus = memory.New(r, l.opts.ToLower, l.opts.MaxCount, l.opts.MinCount)
-	} else {
-		l.corpus = fs.New(r, l.opts.ToLower, l.opts.MaxCount, l.opts.MinCount)
+func (l *lexvec[T]) Train(ctx context.Context, r io.ReadSeeker) error {
+	if l.opts.TimeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.opts.TimeBudget)
+		defer cancel()
 	}
 
-	if err := l.corpus.Load(
+	c, err := modelutil.LoadCorpus(
+		ctx, r,
+		corpus.Key{
+			InMemory: l.opts.DocInMemory,
+			ToLower:  l.opts.ToLower,
+			MaxCount: l.opts.MaxCount,
+			MinCount: l.opts.MinCount,
+		},
 		&corpus.WithCooccurrence{
 			CountType: co.Increment,
 			Window:    l.opts.Window,
 		},
 		l.verbose, l.opts.BatchSize,
-	); err != nil {
+	)
+	if err != nil {
 		return err
 	}
+	l.corpus = c
 
 	dic, dim := l.corpus.Dictionary(), l.opts.Dim
 
+	rnd := rand.New(rand.NewSource(l.opts.Seed))
 	l.param = matrix.New(
 		dic.Len()*2,
 		dim,
-		func(_ int, vec []float64) {
+		func(_ int, vec []T) {
 			for i := 0; i < dim; i++ {
-				vec[i] = (rand.Float64() - 0.5) / float64(dim)
+				vec[i] = T((rnd.Float64() - 0.5) / float64(dim))
 			}
 		},
 	)
 
+	sched, err := schedule.New(l.opts.LRSchedule, schedule.Config{
+		Initlr:      l.opts.Initlr,
+		MinLR:       l.opts.MinLR,
+		Iter:        l.opts.Iter,
+		WarmupRatio: l.opts.WarmupRatio,
+		StepDecay:   l.opts.StepDecay,
+	})
+	if err != nil {
+		return err
+	}
+	l.schedule = sched
+
+	items, err := l.makeItems(l.corpus.Cooccurrence())
+	if err != nil {
+		return err
+	}
+	l.items = items
+	l.sampler = unigram.New(dic, l.opts.Smooth)
 	l.subsampler = subsample.New(dic, l.opts.SubsampleThreshold)
 
+	if l.opts.SamplingType == MatrixSampling {
+		l.progress = progress.New(l.items.len()*l.opts.Iter, l.opts.LogBatch)
+		return l.matrixTrain(ctx)
+	}
+
+	l.progress = progress.New(l.corpus.FilteredLen()*l.opts.Iter, l.opts.LogBatch)
 	if l.opts.DocInMemory {
-		if err := l.train(); err != nil {
+		if err := l.train(ctx); err != nil {
 			return err
 		}
 	} else {
-		if err := l.batchTrain(); err != nil {
+		if err := l.batchTrain(ctx); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (l *lexvec) train() error {
-	items, err := l.makeItems(l.corpus.Cooccurrence())
-	if err != nil {
-		return err
-	}
-
+func (l *lexvec[T]) train(ctx context.Context) error {
 	doc := l.corpus.IndexedDoc()
 	indexPerThread := modelutil.IndexPerThread(
 		l.opts.Goroutines,
 		len(doc),
 	)
 
-	for i := 1; i <= l.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go l.observe(trained, clk)
+	for i := 1; i <= l.opts.Iter && ctx.Err() == nil; i++ {
+		clk := l.startEpoch()
 
-		sem := semaphore.NewWeighted(int64(l.opts.Goroutines))
-		wg := &sync.WaitGroup{}
-
-		for i := 0; i < l.opts.Goroutines; i++ {
-			wg.Add(1)
-			s, e := indexPerThread[i], indexPerThread[i+1]
-			go l.trainPerThread(doc[s:e], items, trained, sem, wg)
+		chunks := modelutil.Intervals(indexPerThread)
+		if l.opts.Shuffle {
+			chunks = shuffle.Chunks(shuffle.Rand(l.opts.Seed, i), len(doc), l.opts.BatchSize)
 		}
+		modelutil.RunWorkers(l.opts.Goroutines, modelutil.Jobs(chunks), func(t int) func([2]int) {
+			rnd := modelutil.NewRandom(l.opts.Seed, i, t)
+			return func(chunk [2]int) {
+				l.trainPerThread(ctx, doc[chunk[0]:chunk[1]], rnd)
+			}
+		})
 
-		wg.Wait()
-		close(trained)
+		l.endEpoch(clk)
 	}
-	return nil
+	return ctx.Err()
 }
 
-func (l *lexvec) batchTrain() error {
-	items, err := l.makeItems(l.corpus.Cooccurrence())
-	if err != nil {
-		return err
-	}
-
-	for i := 1; i <= l.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go l.observe(trained, clk)
-
-		sem := semaphore.NewWeighted(int64(l.opts.Goroutines))
-		wg := &sync.WaitGroup{}
+func (l *lexvec[T]) batchTrain(ctx context.Context) error {
+	for i := 1; i <= l.opts.Iter && ctx.Err() == nil; i++ {
+		clk := l.startEpoch()
 
 		in := make(chan []int, l.opts.Goroutines)
-		go l.corpus.BatchWords(in, l.opts.BatchSize)
-		for doc := range in {
-			wg.Add(1)
-			go l.trainPerThread(doc, items, trained, sem, wg)
-		}
+		go l.corpus.BatchWords(ctx, in, l.opts.BatchSize)
+		modelutil.RunWorkers(l.opts.Goroutines, in, func(t int) func([]int) {
+			rnd := modelutil.NewRandom(l.opts.Seed, i, t)
+			return func(doc []int) {
+				l.trainPerThread(ctx, doc, rnd)
+			}
+		})
 
-		wg.Wait()
-		close(trained)
+		l.endEpoch(clk)
 	}
-	return nil
+	return ctx.Err()
 }
 
-func (l *lexvec) trainPerThread(
+func (l *lexvec[T]) trainPerThread(
+	ctx context.Context,
 	doc []int,
-	items map[uint64]float64,
-	trained chan struct{},
-	sem *semaphore.Weighted,
-	wg *sync.WaitGroup,
-) error {
-	defer func() {
-		wg.Done()
-		sem.Release(1)
-	}()
-
-	if err := sem.Acquire(context.Background(), 1); err != nil {
-		return err
-	}
-
+	rnd *modelutil.Random,
+) {
+	var (
+		sum float64
+		cnt int
+	)
+	counter := l.progress.NewCounter(l.opts.UpdateLRBatch)
+	lr := l.schedule(l.progress.Ratio())
 	for pos, id := range doc {
-		if l.subsampler.Trial(id) {
-			l.trainOne(doc, pos, items)
+		if pos%l.opts.BatchSize == 0 && ctx.Err() != nil {
+			break
+		}
+		if l.subsampler.Trial(id, rnd) {
+			v, n := l.trainOne(doc, pos, lr, rnd)
+			sum += v
+			cnt += n
+		}
+		if counter.Inc() {
+			lr = l.schedule(l.progress.Ratio())
 		}
-		trained <- struct{}{}
 	}
-
-	return nil
+	counter.Flush()
+	l.loss.Add(sum, cnt)
 }
 
-func (l *lexvec) trainOne(doc []int, pos int, items map[uint64]float64) {
-	dic := l.corpus.Dictionary()
-	del := modelutil.NextRandom(l.opts.Window)
+func (l *lexvec[T]) trainOne(doc []int, pos int, lr float64, rnd *modelutil.Random) (float64, int) {
+	var (
+		loss float64
+		n    int
+	)
+	del := rnd.Next(l.opts.Window)
 	for a := del; a < l.opts.Window*2+1-del; a++ {
 		if a == l.opts.Window {
 			continue
@@ -207,76 +249,160 @@ func (l *lexvec) trainOne(doc []int, pos int, items map[uint64]float64) {
 		if c < 0 || c >= len(doc) {
 			continue
 		}
-		enc := encode.EncodeBigram(uint64(doc[pos]), uint64(doc[c]))
-		l.update(doc[pos], doc[c], items[enc])
-		for n := 0; n < l.opts.NegativeSampleSize; n++ {
-			sample := modelutil.NextRandom(dic.Len())
-			enc := encode.EncodeBigram(uint64(doc[pos]), uint64(sample))
-			l.update(doc[pos], sample+dic.Len(), items[enc])
-		}
+		v, k := l.trainPair(doc[pos], doc[c], l.items.at(doc[pos], doc[c]), lr, rnd)
+		loss += v
+		n += k
 	}
+	return loss, n
 }
 
-func (l *lexvec) update(l1, l2 int, f float64) {
-	var diff float64
-	for i := 0; i < l.opts.Dim; i++ {
-		diff += l.param.Slice(l1)[i] * l.param.Slice(l2)[i]
+// matrixTrain visits the non-zero cells of the co-occurrence matrix instead of the windows
+// on the corpus. The cells are permuted every iteration, and the workers train the chunks of
+// BatchSize cells in that order.
+func (l *lexvec[T]) matrixTrain(ctx context.Context) error {
+	size := l.items.len()
+	rows := l.items.cellRows()
+	cells := make([]int, size)
+	for i := range cells {
+		cells[i] = i
 	}
-	diff = (diff - f) * l.currentlr
-	for i := 0; i < l.opts.Dim; i++ {
-		t1 := diff * l.param.Slice(l2)[i]
-		t2 := diff * l.param.Slice(l1)[i]
-		l.param.Slice(l1)[i] -= t1
-		l.param.Slice(l2)[i] -= t2
+	chunks := make([][2]int, 0, (size+l.opts.BatchSize-1)/l.opts.BatchSize)
+	for s := 0; s < size; s += l.opts.BatchSize {
+		e := s + l.opts.BatchSize
+		if e > size {
+			e = size
+		}
+		chunks = append(chunks, [2]int{s, e})
 	}
-}
 
-func (l *lexvec) observe(trained chan struct{}, clk *clock.Clock) {
-	var cnt int
-	for range trained {
-		cnt++
-		if cnt%l.opts.UpdateLRBatch == 0 {
-			if l.currentlr < l.opts.MinLR {
-				l.currentlr = l.opts.MinLR
-			} else {
-				l.currentlr = l.opts.Initlr * (1.0 - float64(cnt)/float64(l.corpus.Len()))
+	for i := 1; i <= l.opts.Iter && ctx.Err() == nil; i++ {
+		clk := l.startEpoch()
+
+		shuffle.Slice(shuffle.Rand(l.opts.Seed, i), cells)
+		modelutil.RunWorkers(l.opts.Goroutines, modelutil.Jobs(chunks), func(t int) func([2]int) {
+			rnd := modelutil.NewRandom(l.opts.Seed, i, t)
+			return func(chunk [2]int) {
+				l.trainCellsPerThread(ctx, cells[chunk[0]:chunk[1]], rows, rnd)
 			}
+		})
+
+		l.endEpoch(clk)
+	}
+	return ctx.Err()
+}
+
+// trainCellsPerThread trains the cells, whose rows are looked up in rows.
+func (l *lexvec[T]) trainCellsPerThread(
+	ctx context.Context,
+	cells []int,
+	rows []int32,
+	rnd *modelutil.Random,
+) {
+	var (
+		sum float64
+		cnt int
+	)
+	counter := l.progress.NewCounter(l.opts.UpdateLRBatch)
+	lr := l.schedule(l.progress.Ratio())
+	for k, i := range cells {
+		if k%l.opts.BatchSize == 0 && ctx.Err() != nil {
+			break
+		}
+		v, n := l.trainPair(int(rows[i]), int(l.items.cols[i]), l.items.vals[i], lr, rnd)
+		sum += v
+		cnt += n
+		if counter.Inc() {
+			lr = l.schedule(l.progress.Ratio())
 		}
+	}
+	counter.Flush()
+	l.loss.Add(sum, cnt)
+}
+
+// trainPair fits the word vector of l1 and the context vector of l2 to f, and the ones
+// of the negative samples drawn by rnd from the smoothed unigram distribution to their values.
+func (l *lexvec[T]) trainPair(l1, l2 int, f, lr float64, rnd *modelutil.Random) (float64, int) {
+	n := l.corpus.Dictionary().Len()
+	loss := l.update(l1, l2+n, lr, f)
+	for s := 0; s < l.opts.NegativeSampleSize; s++ {
+		sample := l.sampler.Sample(rnd)
+		loss += l.update(l1, sample+n, lr, l.items.at(l1, sample))
+	}
+	return loss, l.opts.NegativeSampleSize + 1
+}
+
+func (l *lexvec[T]) update(l1, l2 int, lr, f float64) float64 {
+	v1, v2 := l.param.Slice(l1), l.param.Slice(l2)
+	diff := float64(vecmath.Dot(v1, v2)) - f
+	loss := 0.5 * diff * diff
+	vecmath.CrossAxpy(-T(diff*lr), v1, v2)
+	return loss
+}
+
+func (l *lexvec[T]) startEpoch() *clock.Clock {
+	clk := clock.New()
+	l.progress.StartEpoch(func(trained int64) {
 		l.verbose.Do(func() {
-			if cnt%l.opts.LogBatch == 0 {
-				fmt.Printf("trained %d words %v\r", cnt, clk.AllElapsed())
-			}
+			fmt.Printf("trained %d %s %v loss %f\r", trained, l.unit(), clk.AllElapsed(), l.loss.Current())
 		})
-	}
+	})
+	return clk
+}
+
+func (l *lexvec[T]) endEpoch(clk *clock.Clock) {
+	l.loss.Epoch()
+	l.epochTimes = append(l.epochTimes, clk.AllElapsed())
 	l.verbose.Do(func() {
-		fmt.Printf("trained %d words %v\r\n", cnt, clk.AllElapsed())
+		fmt.Printf("trained %d %s %v loss %f\r\n", l.progress.Epoch(), l.unit(), clk.AllElapsed(), l.loss.Last())
 	})
 }
 
-func (l *lexvec) Save(f io.Writer, typ vector.Type) error {
-	return vector.Save(f, l.corpus.Dictionary(), l.WordVector(typ), l.verbose, l.opts.LogBatch)
+// unit is what the progress counts.
+func (l *lexvec[T]) unit() string {
+	if l.opts.SamplingType == MatrixSampling {
+		return "cells"
+	}
+	return "words"
 }
 
-func (l *lexvec) WordVector(typ vector.Type) *matrix.Matrix {
-	var mat *matrix.Matrix
-	dic := l.corpus.Dictionary()
-	if typ == vector.Agg {
-		mat = matrix.New(dic.Len(), l.opts.Dim,
-			func(row int, vec []float64) {
-				for i := 0; i < l.opts.Dim; i++ {
-					vec[i] = l.param.Slice(row)[i]
-				}
-			},
-		)
-	} else {
-		dic := l.corpus.Dictionary()
-		mat = matrix.New(dic.Len(), l.opts.Dim,
-			func(row int, vec []float64) {
-				for i := 0; i < l.opts.Dim; i++ {
-					vec[i] = l.param.Slice(row)[i] + l.param.Slice(row + dic.Len())[i]
-				}
-			},
-		)
+func (l *lexvec[T]) Save(f io.Writer, typ vector.Type) error {
+	return l.SaveFormat(f, typ, vector.Text)
+}
+
+func (l *lexvec[T]) SaveFormat(f io.Writer, typ vector.Type, format vector.Format) error {
+	mat, err := l.wordVector(typ)
+	if err != nil {
+		return err
 	}
-	return mat
+	return vector.Save(f, l.corpus.Dictionary(), mat, format, l.verbose, l.opts.LogBatch)
+}
+
+func (l *lexvec[T]) Loss() []float64 {
+	return l.loss.History()
+}
+
+func (l *lexvec[T]) Stats() model.Stats {
+	return modelutil.CorpusStats(l.corpus, l.opts.MaxCount, l.opts.MinCount, l.epochTimes)
+}
+
+func (l *lexvec[T]) WordVector(typ vector.Type) (*matrix.Matrix, error) {
+	mat, err := l.wordVector(typ)
+	if err != nil {
+		return nil, err
+	}
+	return matrix.Convert[float64](mat), nil
+}
+
+func (l *lexvec[T]) wordVector(typ vector.Type) (*matrix.MatrixOf[T], error) {
+	if l.param == nil {
+		return nil, model.ErrNotTrained
+	}
+	n := l.corpus.Dictionary().Len()
+	return vector.Compose(typ, n,
+		l.param.Slice,
+		func(row int) []T {
+			return l.param.Slice(row + n)
+		},
+		"",
+	)
 }
@@ -0,0 +1,120 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lexvec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus"
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/memory"
+	"github.com/ynqa/wego/pkg/model/internal/modeltest"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/util/num"
+)
+
+func TestTrain(t *testing.T) {
+	doc := modeltest.Corpus(20000, 500)
+	for _, tc := range []struct {
+		sampling SamplingType
+		inMemory bool
+		shuffle  bool
+	}{
+		{sampling: WindowSampling, inMemory: true, shuffle: false},
+		{sampling: WindowSampling, inMemory: true, shuffle: true},
+		{sampling: WindowSampling, inMemory: false, shuffle: false},
+		{sampling: MatrixSampling, inMemory: true, shuffle: false},
+		{sampling: MatrixSampling, inMemory: false, shuffle: false},
+	} {
+		t.Run(fmt.Sprintf("sampling=%s,inMemory=%t,shuffle=%t", tc.sampling, tc.inMemory, tc.shuffle), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.SamplingType = tc.sampling
+			opts.DocInMemory = tc.inMemory
+			opts.Shuffle = tc.shuffle
+			opts.Goroutines = 1
+			opts.Iter = 2
+			opts.UpdateLRBatch = 100
+			m, err := NewForOptions(opts)
+			assert.NoError(t, err)
+			modeltest.Train(t, m, doc, opts.Iter, opts.Dim)
+		})
+	}
+}
+
+func TestMakeItems(t *testing.T) {
+	opts := DefaultOptions()
+	opts.DocInMemory = true
+	opts.MinCount = 1
+	opts.RelationType = Collocation
+	opts.Window = 1
+	l := newLexvec[float64](opts)
+	l.corpus = memory.New(bytes.NewReader([]byte("a b c a b a")), false, opts.MaxCount, opts.MinCount)
+	assert.NoError(t, l.corpus.Load(context.Background(), &corpus.WithCooccurrence{CountType: co.Increment, Window: opts.Window}, l.verbose, opts.BatchSize))
+
+	items, err := l.makeItems(l.corpus.Cooccurrence())
+	assert.NoError(t, err)
+	dic := l.corpus.Dictionary()
+	id := func(w string) int {
+		i, _ := dic.ID(w)
+		return i
+	}
+	// a-b: 3, b-c: 1, c-a: 1 in both orders.
+	assert.Equal(t, 6, items.len())
+	assert.Equal(t, 3.0, items.at(id("a"), id("b")))
+	assert.Equal(t, 3.0, items.at(id("b"), id("a")))
+	assert.Equal(t, 1.0, items.at(id("c"), id("a")))
+	assert.Equal(t, 0.0, items.at(id("a"), id("a")))
+	rows := items.cellRows()
+	for i := 0; i < items.len(); i++ {
+		assert.Equal(t, items.vals[i], items.at(int(rows[i]), int(items.cols[i])))
+	}
+}
+
+func benchmarkTrain[T num.Float](b *testing.B, precision matrix.Precision, goroutines int) {
+	opts := DefaultOptions()
+	opts.Precision = precision
+	opts.DocInMemory = true
+	opts.Dim = 100
+	opts.Goroutines = goroutines
+	opts.Iter = 1
+	doc := modeltest.Corpus(modeltest.BenchWords, 5000)
+
+	modeltest.Benchmark(b, func() (*matrix.MatrixOf[T], error) {
+		m := newLexvec[T](opts)
+		err := m.Train(context.Background(), bytes.NewReader(doc))
+		return m.param, err
+	})
+}
+
+func BenchmarkTrainFloat32(b *testing.B) {
+	benchmarkTrain[float32](b, matrix.Float32, 1)
+}
+
+func BenchmarkTrainFloat64(b *testing.B) {
+	benchmarkTrain[float64](b, matrix.Float64, 1)
+}
+
+func BenchmarkTrainGoroutines(b *testing.B) {
+	for _, n := range modeltest.BenchGoroutines {
+		b.Run(fmt.Sprintf("goroutines=%d", n), func(b *testing.B) {
+			benchmarkTrain[float32](b, matrix.Float32, n)
+		})
+	}
+}
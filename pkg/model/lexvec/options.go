@@ -17,8 +17,13 @@ package lexvec
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/validate"
 )
 
 type RelationType = string
@@ -30,6 +35,15 @@ const (
 	LogCollocation RelationType = "logco"
 )
 
+type SamplingType = string
+
+const (
+	// WindowSampling trains the pairs in the context window of each word in the corpus.
+	WindowSampling SamplingType = "window"
+	// MatrixSampling trains the non-zero cells of the co-occurrence matrix.
+	MatrixSampling SamplingType = "matrix"
+)
+
 var (
 	defaultBatchSize          = 10000
 	defaultDim                = 10
@@ -37,17 +51,25 @@ var (
 	defaultGoroutines         = runtime.NumCPU()
 	defaultInitlr             = 0.025
 	defaultIter               = 15
+	defaultLRSchedule         = schedule.Linear
 	defaultLogBatch           = 100000
 	defaultMaxCount           = -1
 	defaultMinCount           = 5
 	defaultMinLR              = defaultInitlr * 1.0e-4
 	defaultNegativeSampleSize = 5
+	defaultPrecision          = matrix.Float64
 	defaultRelationType       = PPMI
+	defaultSamplingType       = WindowSampling
+	defaultSeed               = int64(1)
+	defaultShuffle            = false
 	defaultSmooth             = 0.75
+	defaultStepDecay          = 0.5
 	defaultSubsampleThreshold = 1.0e-3
+	defaultTimeBudget         = time.Duration(0)
 	defaultToLower            = false
 	defaultUpdateLRBatch      = 100000
 	defaultVerbose            = false
+	defaultWarmupRatio        = 0.1
 	defaultWindow             = 5
 )
 
@@ -58,17 +80,25 @@ type Options struct {
 	Goroutines         int
 	Initlr             float64
 	Iter               int
+	LRSchedule         schedule.Type
 	LogBatch           int
 	MaxCount           int
 	MinCount           int
 	MinLR              float64
 	NegativeSampleSize int
+	Precision          matrix.Precision
 	RelationType       RelationType
+	SamplingType       SamplingType
+	Seed               int64
+	Shuffle            bool
 	Smooth             float64
+	StepDecay          float64
 	SubsampleThreshold float64
+	TimeBudget         time.Duration
 	ToLower            bool
 	UpdateLRBatch      int
 	Verbose            bool
+	WarmupRatio        float64
 	Window             int
 }
 
@@ -80,39 +110,82 @@ func DefaultOptions() Options {
 		Goroutines:         defaultGoroutines,
 		Initlr:             defaultInitlr,
 		Iter:               defaultIter,
+		LRSchedule:         defaultLRSchedule,
 		LogBatch:           defaultLogBatch,
 		MaxCount:           defaultMaxCount,
 		MinCount:           defaultMinCount,
 		MinLR:              defaultMinLR,
 		NegativeSampleSize: defaultNegativeSampleSize,
+		Precision:          defaultPrecision,
 		RelationType:       defaultRelationType,
+		SamplingType:       defaultSamplingType,
+		Seed:               defaultSeed,
+		Shuffle:            defaultShuffle,
 		Smooth:             defaultSmooth,
+		StepDecay:          defaultStepDecay,
 		SubsampleThreshold: defaultSubsampleThreshold,
+		TimeBudget:         defaultTimeBudget,
 		ToLower:            defaultToLower,
 		UpdateLRBatch:      defaultUpdateLRBatch,
 		Verbose:            defaultVerbose,
+		WarmupRatio:        defaultWarmupRatio,
 		Window:             defaultWindow,
 	}
 }
+
+// Validate reports all invalid values of Options at once.
+func (opts Options) Validate() error {
+	v := validate.New()
+	v.Positive("BatchSize", opts.BatchSize)
+	v.Positive("Dim", opts.Dim)
+	v.Positive("Goroutines", opts.Goroutines)
+	v.PositiveFloat("Initlr", opts.Initlr)
+	v.Positive("Iter", opts.Iter)
+	v.OneOf("LRSchedule", opts.LRSchedule, schedule.Linear, schedule.Constant, schedule.Cosine, schedule.Step, schedule.Warmup)
+	v.Positive("LogBatch", opts.LogBatch)
+	v.NonNegativeFloat("MinLR", opts.MinLR)
+	v.Check(opts.MinLR <= opts.Initlr, "MinLR", opts.MinLR, fmt.Sprintf("must be <= Initlr=%v", opts.Initlr))
+	v.NonNegative("NegativeSampleSize", opts.NegativeSampleSize)
+	v.OneOf("Precision", opts.Precision, matrix.Float64, matrix.Float32)
+	v.OneOf("RelationType", opts.RelationType, PPMI, PMI, Collocation, LogCollocation)
+	v.OneOf("SamplingType", opts.SamplingType, WindowSampling, MatrixSampling)
+	v.NonNegativeFloat("Smooth", opts.Smooth)
+	v.Range("StepDecay", opts.StepDecay, 0, 1)
+	v.NonNegativeFloat("SubsampleThreshold", opts.SubsampleThreshold)
+	v.Check(opts.TimeBudget >= 0, "TimeBudget", opts.TimeBudget, "must be >= 0")
+	v.Positive("UpdateLRBatch", opts.UpdateLRBatch)
+	v.Check(0 <= opts.WarmupRatio && opts.WarmupRatio < 1, "WarmupRatio", opts.WarmupRatio, "must be in [0, 1)")
+	v.Positive("Window", opts.Window)
+	return v.Err()
+}
+
 func LoadForCmd(cmd *cobra.Command, opts *Options) {
-	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train")
-	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector")
-	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine")
+	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train (> 0)")
+	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector (> 0)")
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine (> 0)")
 	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", defaultDocInMemory, "whether to store the doc in memory")
-	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate")
-	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration")
-	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words")
+	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate (> 0)")
+	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration (> 0)")
+	cmd.Flags().StringVar(&opts.LRSchedule, "lr-schedule", defaultLRSchedule, fmt.Sprintf("learning rate schedule over total training progress. One of: %s|%s|%s|%s|%s", schedule.Linear, schedule.Constant, schedule.Cosine, schedule.Step, schedule.Warmup))
+	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words (> 0)")
 	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words")
 	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words")
-	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", defaultMinLR, "lower limit of learning rate")
-	cmd.Flags().IntVar(&opts.NegativeSampleSize, "sample", defaultNegativeSampleSize, "negative sample size")
+	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", defaultMinLR, "lower limit of learning rate (in [0, initlr])")
+	cmd.Flags().IntVar(&opts.NegativeSampleSize, "sample", defaultNegativeSampleSize, "negative sample size (>= 0)")
+	cmd.Flags().StringVar(&opts.Precision, "precision", defaultPrecision, fmt.Sprintf("floating point type to store parameters. One of: %s|%s", matrix.Float64, matrix.Float32))
 	cmd.Flags().StringVar(&opts.RelationType, "rel", defaultRelationType, fmt.Sprintf("relation type for co-occurrence words. One of %s|%s|%s|%s", PPMI, PMI, Collocation, LogCollocation))
-	cmd.Flags().Float64Var(&opts.Smooth, "smooth", defaultSmooth, "smoothing value for co-occurence value")
-	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling")
+	cmd.Flags().StringVar(&opts.SamplingType, "sampling", defaultSamplingType, fmt.Sprintf("sampling type for the pairs to train. One of %s|%s", WindowSampling, MatrixSampling))
+	cmd.Flags().Int64Var(&opts.Seed, "seed", defaultSeed, "random seed for initialization, sampling and shuffling")
+	cmd.Flags().BoolVar(&opts.Shuffle, "shuffle", defaultShuffle, "whether to shuffle the chunks of batch size words every iteration (for in-memory window sampling only; matrix sampling always shuffles the cells)")
+	cmd.Flags().Float64Var(&opts.Smooth, "smooth", defaultSmooth, "smoothing value for context frequencies in PPMI and the distribution of negative samples (>= 0)")
+	cmd.Flags().Float64Var(&opts.StepDecay, "step-decay", defaultStepDecay, "factor to multiply learning rate at each epoch (for step schedule only; in [0, 1])")
+	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling (>= 0)")
+	cmd.Flags().DurationVar(&opts.TimeBudget, "time-budget", defaultTimeBudget, "wall-clock budget for training, e.g. 30m (no limit if zero; >= 0)")
 	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on corpus convert to lowercase or not")
-	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", defaultUpdateLRBatch, "batch size to update learning rate")
+	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", defaultUpdateLRBatch, "batch size to update learning rate (> 0)")
 	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
-	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size")
+	cmd.Flags().Float64Var(&opts.WarmupRatio, "warmup-ratio", defaultWarmupRatio, "ratio of training to increase learning rate linearly (for warmup schedule only; in [0, 1))")
+	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size (> 0)")
 
 }
 
@@ -154,6 +227,12 @@ func Iter(v int) ModelOption {
 	})
 }
 
+func LRSchedule(v schedule.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRSchedule = v
+	})
+}
+
 func LogBatch(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.LogBatch = v
@@ -184,24 +263,60 @@ func NegativeSampleSize(v int) ModelOption {
 	})
 }
 
+func Precision(typ matrix.Precision) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Precision = typ
+	})
+}
+
 func Relation(typ RelationType) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.RelationType = typ
 	})
 }
 
+func Sampling(typ SamplingType) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SamplingType = typ
+	})
+}
+
+func Seed(v int64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Seed = v
+	})
+}
+
+func Shuffle(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Shuffle = v
+	})
+}
+
 func Smooth(v float64) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Smooth = v
 	})
 }
 
+func StepDecay(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.StepDecay = v
+	})
+}
+
 func SubsampleThreshold(v float64) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.SubsampleThreshold = v
 	})
 }
 
+func TimeBudget(v time.Duration) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.TimeBudget = v
+	})
+}
+
 func ToLower() ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.ToLower = true
@@ -220,6 +335,12 @@ func Verbose() ModelOption {
 	})
 }
 
+func WarmupRatio(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.WarmupRatio = v
+	})
+}
+
 func Window(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Window = v
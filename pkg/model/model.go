@@ -15,14 +15,83 @@
 package model
 
 import (
+	"bytes"
+	"context"
 	"io"
+	"time"
 
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 )
 
+// ErrNotTrained is returned when the vectors are requested before training.
+var ErrNotTrained = errors.New("model has not been trained")
+
 type Model interface {
-	Train(io.ReadSeeker) error
+	Train(context.Context, io.ReadSeeker) error
 	Save(io.Writer, vector.Type) error
-	WordVector(vector.Type) *matrix.Matrix
+	WordVector(vector.Type) (*matrix.Matrix, error)
+	Loss() []float64
+}
+
+// Stats is the summary of the corpus and the training time of a run.
+type Stats struct {
+	// Vocab and FilteredVocab are the number of the words before and after filtering by
+	// MinCount and MaxCount.
+	Vocab         int
+	FilteredVocab int
+	// Tokens and FilteredTokens are the number of the tokens (or the pairs for word2vecf)
+	// on the corpus before and after filtering.
+	Tokens         int
+	FilteredTokens int
+	// EpochTimes are the elapsed time of each epoch.
+	EpochTimes []time.Duration
+}
+
+// StatsReporter is implemented by the models which report Stats after training.
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// FormatSaver is implemented by the models which save the vectors in the formats other than
+// vector.Text, e.g. vector.Binary. Save is the same as SaveFormat in vector.Text.
+type FormatSaver interface {
+	SaveFormat(io.Writer, vector.Type, vector.Format) error
+}
+
+// SaveFormat saves the vectors of mod in format. The models which don't implement FormatSaver
+// save them in vector.Text by Save. For the other formats, the words are taken from Save, which
+// writes the rows of WordVector in order, and the values from WordVector in full precision.
+func SaveFormat(w io.Writer, mod Model, typ vector.Type, format vector.Format) error {
+	if s, ok := mod.(FormatSaver); ok {
+		return s.SaveFormat(w, typ, format)
+	}
+	if err := vector.ValidateFormat(format); err != nil {
+		return err
+	}
+	if format == vector.Text {
+		return mod.Save(w, typ)
+	}
+	var buf bytes.Buffer
+	if err := mod.Save(&buf, typ); err != nil {
+		return err
+	}
+	embs, err := embedding.Load(&buf)
+	if err != nil {
+		return err
+	}
+	mat, err := mod.WordVector(typ)
+	if err != nil {
+		return err
+	}
+	if len(embs) != mat.Row() {
+		return errors.Errorf("different for the number of saved words and row of matrix: %d, %d", len(embs), mat.Row())
+	}
+	for i := range embs {
+		embs[i].Dim, embs[i].Vector = mat.Col(), mat.Slice(i)
+	}
+	return embedding.SaveFormat(w, embs, format)
 }
@@ -0,0 +1,57 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+)
+
+func TestSaveFormat(t *testing.T) {
+	mod := &fake{}
+	for _, format := range []vector.Format{vector.Text, vector.Vec, vector.Binary} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, SaveFormat(&buf, mod, vector.Word, format))
+			var (
+				embs embedding.Embeddings
+				err  error
+			)
+			if format == vector.Binary {
+				embs, err = embedding.LoadBinary(&buf)
+			} else {
+				if format == vector.Vec {
+					assert.Equal(t, "2 2\n", buf.String()[:4])
+				}
+				embs, err = embedding.Load(&buf)
+			}
+			assert.NoError(t, err)
+			assert.Len(t, embs, 2)
+			assert.Equal(t, "banana", embs[1].Word)
+			if format == vector.Binary {
+				// the values are not rounded by the text saved by fake.Save.
+				assert.InEpsilonSlice(t, fakeVectors[1], embs[1].Vector, 1e-6)
+			} else {
+				assert.InDeltaSlice(t, fakeVectors[1], embs[1].Vector, 1e-6)
+			}
+		})
+	}
+	assert.Error(t, SaveFormat(&bytes.Buffer{}, mod, vector.Word, "csv"))
+}
@@ -0,0 +1,103 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loss
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Loss accumulates the objective value which is reported by training threads,
+// and keeps the mean loss per epoch.
+type Loss struct {
+	mu sync.Mutex
+
+	sum     float64
+	cnt     int
+	history []float64
+}
+
+func New() *Loss {
+	return &Loss{
+		history: make([]float64, 0),
+	}
+}
+
+// Add accumulates the sum of loss for n examples.
+func (l *Loss) Add(sum float64, n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sum += sum
+	l.cnt += n
+}
+
+// Current returns the mean loss of examples added in the running epoch.
+func (l *Loss) Current() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.mean()
+}
+
+// Epoch closes the running epoch, records its mean loss and returns it.
+func (l *Loss) Epoch() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	m := l.mean()
+	l.history = append(l.history, m)
+	l.sum, l.cnt = 0, 0
+	return m
+}
+
+// Last returns the mean loss of the latest closed epoch.
+func (l *Loss) Last() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.history) == 0 {
+		return 0
+	}
+	return l.history[len(l.history)-1]
+}
+
+// History returns the mean loss for each closed epoch.
+func (l *Loss) History() []float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	res := make([]float64, len(l.history))
+	copy(res, l.history)
+	return res
+}
+
+func (l *Loss) mean() float64 {
+	if l.cnt == 0 {
+		return 0
+	}
+	return l.sum / float64(l.cnt)
+}
+
+// WriteCSV writes the loss curve with the header `epoch,loss`.
+func WriteCSV(w io.Writer, history []float64) error {
+	writer := bufio.NewWriter(w)
+	if _, err := fmt.Fprintln(writer, "epoch,loss"); err != nil {
+		return err
+	}
+	for i, v := range history {
+		if _, err := fmt.Fprintf(writer, "%d,%f\n", i+1, v); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,41 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loss
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoss(t *testing.T) {
+	l := New()
+	l.Add(3, 2)
+	l.Add(1, 2)
+	assert.Equal(t, 1., l.Current())
+	assert.Equal(t, 1., l.Epoch())
+	assert.Equal(t, 0., l.Current())
+	l.Add(1, 2)
+	assert.Equal(t, 0.5, l.Epoch())
+	assert.Equal(t, 0.5, l.Last())
+	assert.Equal(t, []float64{1, 0.5}, l.History())
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCSV(&buf, []float64{1, 0.5}))
+	assert.Equal(t, "epoch,loss\n1,1.000000\n2,0.500000\n", buf.String())
+}
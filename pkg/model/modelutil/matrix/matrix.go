@@ -14,15 +14,29 @@
 
 package matrix
 
-type Matrix struct {
-	array []float64
+import (
+	"github.com/ynqa/wego/pkg/util/num"
+)
+
+type Precision = string
+
+const (
+	Float32 Precision = "float32"
+	Float64 Precision = "float64"
+)
+
+// MatrixOf is the dense row-major matrix whose elements are stored as T.
+type MatrixOf[T num.Float] struct {
+	array []T
 	row   int
 	col   int
 }
 
-func New(row, col int, fn func(int, []float64)) *Matrix {
-	mat := &Matrix{
-		array: make([]float64, row*col),
+type Matrix = MatrixOf[float64]
+
+func New[T num.Float](row, col int, fn func(int, []T)) *MatrixOf[T] {
+	mat := &MatrixOf[T]{
+		array: make([]T, row*col),
 		row:   row,
 		col:   col,
 	}
@@ -32,19 +46,28 @@ func New(row, col int, fn func(int, []float64)) *Matrix {
 	return mat
 }
 
-func (m *Matrix) startIndex(id int) int {
+// Convert copies mat into the matrix whose elements are stored as U.
+func Convert[U, T num.Float](mat *MatrixOf[T]) *MatrixOf[U] {
+	return New(mat.row, mat.col, func(row int, vec []U) {
+		for i, v := range mat.Slice(row) {
+			vec[i] = U(v)
+		}
+	})
+}
+
+func (m *MatrixOf[T]) startIndex(id int) int {
 	return id * m.col
 }
 
-func (m *Matrix) Row() int {
+func (m *MatrixOf[T]) Row() int {
 	return m.row
 }
 
-func (m *Matrix) Col() int {
+func (m *MatrixOf[T]) Col() int {
 	return m.col
 }
 
-func (m *Matrix) Slice(id int) []float64 {
+func (m *MatrixOf[T]) Slice(id int) []T {
 	start := m.startIndex(id)
 	return m.array[start : start+m.col]
 }
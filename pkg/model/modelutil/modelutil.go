@@ -15,17 +15,87 @@
 package modelutil
 
 import (
+	"context"
+	"io"
 	"math"
-)
+	"sync"
+	"time"
 
-var (
-	next uint64 = 1
+	"github.com/ynqa/wego/pkg/corpus"
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/fs"
+	"github.com/ynqa/wego/pkg/corpus/memory"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/util/verbose"
 )
 
-// NextRandom is linear congruential generator (rand.Intn).
-func NextRandom(value int) int {
-	next = next*uint64(25214903917) + 11
-	return int(next % uint64(value))
+// Random is linear congruential generator (rand.Intn). It is not safe for concurrent use,
+// so that each worker has its own one.
+type Random struct {
+	next uint64
+}
+
+// NewRandom returns the generator whose state is determined by seed and keys (e.g. epoch and chunk),
+// in the same way as shuffle.Rand.
+func NewRandom(seed int64, keys ...int) *Random {
+	s := seed
+	for _, k := range keys {
+		s = s*1000003 + int64(k) + 1
+	}
+	return &Random{
+		next: uint64(s),
+	}
+}
+
+// Next returns the random number in [0, value).
+func (r *Random) Next(value int) int {
+	r.next = r.next*uint64(25214903917) + 11
+	return int(r.next % uint64(value))
+}
+
+// Float64 returns the random number in [0, 1).
+func (r *Random) Float64() float64 {
+	return float64(r.Next(1<<53)) / (1 << 53)
+}
+
+// Jobs returns the channel which receives jobs in order and is closed after the last one.
+func Jobs[J any](jobs []J) <-chan J {
+	ch := make(chan J)
+	go func() {
+		defer close(ch)
+		for _, job := range jobs {
+			ch <- job
+		}
+	}()
+	return ch
+}
+
+// RunWorkers starts n workers which receive the jobs from in until it's closed, and waits for them.
+// Each worker calls newWorker with its index once, and processes its jobs by the returned function,
+// so that the state of the worker (e.g. Random) is owned by the worker. The jobs are received in
+// order, which is the order of training for a single worker.
+func RunWorkers[J any](n int, in <-chan J, newWorker func(worker int) func(J)) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fn := newWorker(i)
+			for job := range in {
+				fn(job)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// Intervals returns the intervals [s, e) between the indices, e.g. by IndexPerThread.
+func Intervals(indices []int) [][2]int {
+	res := make([][2]int, len(indices)-1)
+	for i := range res {
+		res[i] = [2]int{indices[i], indices[i+1]}
+	}
+	return res
 }
 
 // IndexPerThread creates interval of indices per thread.
@@ -39,3 +109,53 @@ func IndexPerThread(threadSize, dataSize int) []int {
 	}
 	return indexPerThread
 }
+
+// LogLoss returns the negative log-likelihood of label for x:
+// -log(sigmoid(x)) for label=1 and -log(1 - sigmoid(x)) for label=0.
+func LogLoss(label int, x float64) float64 {
+	if label == 0 {
+		x = -x
+	}
+	if x < 0 {
+		return -x + math.Log1p(math.Exp(x))
+	}
+	return math.Log1p(math.Exp(-x))
+}
+
+// CorpusStats returns the statistics of c whose words are filtered by maxCount and minCount.
+// It returns the zero value if c is not loaded yet.
+func CorpusStats(c corpus.Corpus, maxCount, minCount int, epochTimes []time.Duration) model.Stats {
+	if c == nil {
+		return model.Stats{}
+	}
+	dic := c.Dictionary()
+	filters := cpsutil.Filters{
+		cpsutil.MaxCount(maxCount),
+		cpsutil.MinCount(minCount),
+	}
+	return model.Stats{
+		Vocab:          dic.Len(),
+		FilteredVocab:  filters.Vocab(dic),
+		Tokens:         c.Len(),
+		FilteredTokens: c.FilteredLen(),
+		EpochTimes:     append([]time.Duration(nil), epochTimes...),
+	}
+}
+
+// LoadCorpus loads the corpus of r in memory or on the file system by key.InMemory.
+// The loaded corpus is shared by corpus.Load if ctx has corpus.Cache.
+func LoadCorpus(
+	ctx context.Context,
+	r io.ReadSeeker,
+	key corpus.Key,
+	with *corpus.WithCooccurrence,
+	verbose *verbose.Verbose,
+	logBatch int,
+) (corpus.Corpus, error) {
+	return corpus.Load(ctx, key, r, func(doc io.ReadSeeker) corpus.Corpus {
+		if key.InMemory {
+			return memory.New(doc, key.ToLower, key.MaxCount, key.MinCount)
+		}
+		return fs.New(doc, key.ToLower, key.MaxCount, key.MinCount)
+	}, with, verbose, logBatch)
+}
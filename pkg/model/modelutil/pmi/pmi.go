@@ -0,0 +1,62 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pmi
+
+import (
+	"math"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+)
+
+// PMI computes the pointwise mutual information of the word pairs from the co-occurrence
+// count and the word frequencies. The frequencies of the second words (contexts) are
+// raised to the power of smooth (context distribution smoothing, Levy et al. 2015).
+type PMI struct {
+	logFreq       []float64
+	logSmoothFreq []float64
+	logTotal      float64
+}
+
+// New precomputes the logarithm of the frequencies in dic, where total is the corpus size.
+func New(dic *dictionary.Dictionary, total int, smooth float64) *PMI {
+	p := &PMI{
+		logFreq:       make([]float64, dic.Len()),
+		logSmoothFreq: make([]float64, dic.Len()),
+		logTotal:      smooth * math.Log(float64(total)),
+	}
+	for id := 0; id < dic.Len(); id++ {
+		lf := math.Log(float64(dic.IDFreq(id)))
+		p.logFreq[id], p.logSmoothFreq[id] = lf, smooth*lf
+	}
+	return p
+}
+
+// Value returns log(co * total^smooth / (freq(l1) * freq(l2)^smooth)).
+func (p *PMI) Value(l1, l2 int, co float64) float64 {
+	return math.Log(co) - p.logFreq[l1] - p.logSmoothFreq[l2] + p.logTotal
+}
+
+// Positive returns max(Value - log(shift), 0), which is shifted positive PMI.
+// shift=1 means no shift.
+func (p *PMI) Positive(l1, l2 int, co, shift float64) float64 {
+	if co == 0 {
+		return 0
+	}
+	v := p.Value(l1, l2, co) - math.Log(shift)
+	if v < 0 {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,39 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pmi
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+)
+
+func TestPMI(t *testing.T) {
+	dic := dictionary.New()
+	dic.Add("a", "a", "b", "b", "b", "b")
+	p := New(dic, 6, 1)
+
+	assert.InDelta(t, math.Log(3*6./(2*4)), p.Value(0, 1, 3), 1e-9)
+	assert.InDelta(t, math.Log(3*6./(2*4)), p.Positive(0, 1, 3, 1), 1e-9)
+	assert.Equal(t, 0., p.Positive(0, 1, 1, 1))
+	assert.Equal(t, 0., p.Positive(0, 1, 3, 3))
+	assert.Equal(t, 0., p.Positive(0, 1, 0, 1))
+
+	smoothed := New(dic, 6, 0.5)
+	assert.InDelta(t, math.Log(3)-math.Log(2)-0.5*math.Log(4)+0.5*math.Log(6), smoothed.Value(0, 1, 3), 1e-9)
+}
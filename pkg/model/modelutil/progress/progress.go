@@ -0,0 +1,102 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package progress
+
+import (
+	"sync/atomic"
+)
+
+// Progress counts the trained units (words or items) over all epochs.
+// Each worker counts on its own Counter and aggregates it into Progress once per batch,
+// so that the workers never synchronize on every unit.
+type Progress struct {
+	done  int64
+	base  int64
+	total int64
+
+	logBatch int64
+	log      func(trained int64)
+}
+
+// New creates Progress for total units, and calls the log function of each epoch
+// whenever the number of trained units in the epoch passes a multiple of logBatch.
+func New(total, logBatch int) *Progress {
+	return &Progress{
+		total:    int64(total),
+		logBatch: int64(logBatch),
+	}
+}
+
+// StartEpoch resets the count of the running epoch and sets the log function for it.
+// It must be called while no worker is running.
+func (p *Progress) StartEpoch(log func(trained int64)) {
+	p.base = atomic.LoadInt64(&p.done)
+	p.log = log
+}
+
+// Epoch returns the number of trained units in the running epoch.
+func (p *Progress) Epoch() int64 {
+	return atomic.LoadInt64(&p.done) - p.base
+}
+
+// Ratio returns the trained fraction of all epochs in [0, 1].
+func (p *Progress) Ratio() float64 {
+	if p.total <= 0 {
+		return 1
+	}
+	r := float64(atomic.LoadInt64(&p.done)) / float64(p.total)
+	if r > 1 {
+		return 1
+	}
+	return r
+}
+
+// Counter counts the trained units of one worker.
+type Counter struct {
+	progress *Progress
+	batch    int
+	cnt      int
+}
+
+// NewCounter creates Counter which aggregates into p every batch units.
+func (p *Progress) NewCounter(batch int) *Counter {
+	return &Counter{
+		progress: p,
+		batch:    batch,
+	}
+}
+
+// Inc counts one unit, and returns true when the count has been aggregated into Progress.
+func (c *Counter) Inc() bool {
+	c.cnt++
+	if c.cnt < c.batch {
+		return false
+	}
+	c.Flush()
+	return true
+}
+
+// Flush aggregates the remaining count into Progress.
+func (c *Counter) Flush() {
+	if c.cnt == 0 {
+		return
+	}
+	p, n := c.progress, int64(c.cnt)
+	c.cnt = 0
+	after := atomic.AddInt64(&p.done, n) - p.base
+	if p.log != nil && p.logBatch > 0 && (after-n)/p.logBatch != after/p.logBatch {
+		p.log(after)
+	}
+}
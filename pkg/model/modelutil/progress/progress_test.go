@@ -0,0 +1,69 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package progress
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgress(t *testing.T) {
+	p := New(400, 50)
+	var logged []int64
+	p.StartEpoch(func(trained int64) {
+		logged = append(logged, trained)
+	})
+
+	c := p.NewCounter(30)
+	var flushed int
+	for i := 0; i < 100; i++ {
+		if c.Inc() {
+			flushed++
+		}
+	}
+	assert.Equal(t, 3, flushed)
+	assert.Equal(t, int64(90), p.Epoch())
+	c.Flush()
+	assert.Equal(t, int64(100), p.Epoch())
+	assert.Equal(t, 0.25, p.Ratio())
+	assert.Equal(t, []int64{60, 100}, logged)
+
+	p.StartEpoch(nil)
+	assert.Equal(t, int64(0), p.Epoch())
+	assert.Equal(t, 0.25, p.Ratio())
+}
+
+func TestProgressConcurrent(t *testing.T) {
+	p := New(8000, 0)
+	p.StartEpoch(nil)
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := p.NewCounter(7)
+			for j := 0; j < 1000; j++ {
+				c.Inc()
+			}
+			c.Flush()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(8000), p.Epoch())
+	assert.Equal(t, 1., p.Ratio())
+}
@@ -0,0 +1,111 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+type Type = string
+
+const (
+	Linear   Type = "linear"
+	Constant Type = "constant"
+	Cosine   Type = "cosine"
+	Step     Type = "step"
+	Warmup   Type = "warmup"
+)
+
+func InvalidTypeError(typ Type) error {
+	return errors.Errorf("invalid lr schedule: %s not in %s|%s|%s|%s|%s", typ, Linear, Constant, Cosine, Step, Warmup)
+}
+
+// Schedule returns the learning rate for the progress of whole training,
+// that is the ratio of trained units (words or items) to the total across all epochs, in [0, 1].
+type Schedule func(progress float64) float64
+
+type Config struct {
+	Initlr float64
+	MinLR  float64
+	Iter   int
+	// WarmupRatio is the ratio of progress to increase the learning rate linearly (for warmup only).
+	WarmupRatio float64
+	// StepDecay is the factor to multiply the learning rate at each epoch (for step only).
+	StepDecay float64
+}
+
+func New(typ Type, conf Config) (Schedule, error) {
+	switch typ {
+	case Linear:
+		return linear(conf), nil
+	case Constant:
+		return constant(conf), nil
+	case Cosine:
+		return cosine(conf), nil
+	case Step:
+		return step(conf), nil
+	case Warmup:
+		return warmup(conf), nil
+	default:
+		return nil, InvalidTypeError(typ)
+	}
+}
+
+// linear decays the learning rate over total training words like the reference word2vec.
+func linear(conf Config) Schedule {
+	return Schedule(func(progress float64) float64 {
+		return floor(conf.Initlr*(1-clip(progress)), conf.MinLR)
+	})
+}
+
+func constant(conf Config) Schedule {
+	return Schedule(func(float64) float64 {
+		return conf.Initlr
+	})
+}
+
+func cosine(conf Config) Schedule {
+	return Schedule(func(progress float64) float64 {
+		return conf.MinLR + (conf.Initlr-conf.MinLR)*0.5*(1+math.Cos(math.Pi*clip(progress)))
+	})
+}
+
+func step(conf Config) Schedule {
+	return Schedule(func(progress float64) float64 {
+		epoch := math.Floor(clip(progress) * float64(conf.Iter))
+		return floor(conf.Initlr*math.Pow(conf.StepDecay, epoch), conf.MinLR)
+	})
+}
+
+// warmup increases the learning rate linearly up to Initlr, and then decays it linearly.
+func warmup(conf Config) Schedule {
+	return Schedule(func(progress float64) float64 {
+		progress = clip(progress)
+		if progress < conf.WarmupRatio {
+			return floor(conf.Initlr*progress/conf.WarmupRatio, conf.MinLR)
+		}
+		return floor(conf.Initlr*(1-progress)/(1-conf.WarmupRatio), conf.MinLR)
+	})
+}
+
+func clip(progress float64) float64 {
+	return math.Max(0, math.Min(1, progress))
+}
+
+func floor(lr, minlr float64) float64 {
+	return math.Max(lr, minlr)
+}
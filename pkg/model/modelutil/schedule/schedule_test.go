@@ -0,0 +1,78 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	conf := Config{
+		Initlr:      1,
+		MinLR:       0.01,
+		Iter:        4,
+		WarmupRatio: 0.2,
+		StepDecay:   0.5,
+	}
+
+	testCases := []struct {
+		typ      Type
+		progress []float64
+		expect   []float64
+	}{
+		{
+			typ:      Linear,
+			progress: []float64{0, 0.5, 1, 2},
+			expect:   []float64{1, 0.5, 0.01, 0.01},
+		},
+		{
+			typ:      Constant,
+			progress: []float64{0, 0.5, 1},
+			expect:   []float64{1, 1, 1},
+		},
+		{
+			typ:      Cosine,
+			progress: []float64{0, 0.5, 1},
+			expect:   []float64{1, 0.505, 0.01},
+		},
+		{
+			typ:      Step,
+			progress: []float64{0, 0.3, 0.6, 0.8},
+			expect:   []float64{1, 0.5, 0.25, 0.125},
+		},
+		{
+			typ:      Warmup,
+			progress: []float64{0, 0.1, 0.2, 0.6, 1},
+			expect:   []float64{0.01, 0.5, 1, 0.5, 0.01},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.typ, func(t *testing.T) {
+			fn, err := New(tc.typ, conf)
+			assert.NoError(t, err)
+			for i, p := range tc.progress {
+				assert.InDelta(t, tc.expect[i], fn(p), 1e-9)
+			}
+		})
+	}
+}
+
+func TestScheduleWithInvalidType(t *testing.T) {
+	_, err := New(Type("invalid"), Config{})
+	assert.Error(t, err)
+}
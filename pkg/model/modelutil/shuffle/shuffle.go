@@ -0,0 +1,51 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shuffle
+
+import (
+	"math/rand"
+)
+
+// Rand returns the random source which is determined by seed and keys (e.g. epoch and shard),
+// so that the order of each epoch and shard is reproducible and independent of the others.
+func Rand(seed int64, keys ...int) *rand.Rand {
+	s := seed
+	for _, k := range keys {
+		s = s*1000003 + int64(k) + 1
+	}
+	return rand.New(rand.NewSource(s))
+}
+
+// Slice shuffles items in place by Fisher-Yates.
+func Slice[E any](rnd *rand.Rand, items []E) {
+	for i := len(items) - 1; i > 0; i-- {
+		j := rnd.Intn(i + 1)
+		items[i], items[j] = items[j], items[i]
+	}
+}
+
+// Chunks splits [0, n) into the chunks of size, and returns their intervals [s, e) in random order.
+func Chunks(rnd *rand.Rand, n, size int) [][2]int {
+	res := make([][2]int, 0, (n+size-1)/size)
+	for s := 0; s < n; s += size {
+		e := s + size
+		if e > n {
+			e = n
+		}
+		res = append(res, [2]int{s, e})
+	}
+	Slice(rnd, res)
+	return res
+}
@@ -0,0 +1,48 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shuffle
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlice(t *testing.T) {
+	items := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	Slice(Rand(1, 0), items)
+	assert.NotEqual(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+
+	again := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	Slice(Rand(1, 0), again)
+	assert.Equal(t, items, again)
+
+	next := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	Slice(Rand(1, 1), next)
+	assert.NotEqual(t, items, next)
+
+	sort.Ints(items)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+}
+
+func TestChunks(t *testing.T) {
+	chunks := Chunks(Rand(1), 10, 4)
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i][0] < chunks[j][0]
+	})
+	assert.Equal(t, [][2]int{{0, 4}, {4, 8}, {8, 10}}, chunks)
+	assert.Empty(t, Chunks(Rand(1), 0, 4))
+}
@@ -0,0 +1,82 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package unigram samples the words from the unigram distribution raised to a power,
+// which is used to draw the negative samples.
+package unigram
+
+import (
+	"math"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+)
+
+const probBits = 1 << 30
+
+// Sampler draws the word ids in proportion to freq^power by the alias method,
+// so that a sample costs O(1) whatever the vocabulary size is.
+type Sampler struct {
+	prob  []int
+	alias []int
+}
+
+// New builds the sampler for the frequencies in dic.
+func New(dic *dictionary.Dictionary, power float64) *Sampler {
+	n := dic.Len()
+	weights := make([]float64, n)
+	var total float64
+	for id := 0; id < n; id++ {
+		weights[id] = math.Pow(float64(dic.IDFreq(id)), power)
+		total += weights[id]
+	}
+
+	s := &Sampler{
+		prob:  make([]int, n),
+		alias: make([]int, n),
+	}
+	small, large := make([]int, 0, n), make([]int, 0, n)
+	for id, w := range weights {
+		weights[id] = w * float64(n) / total
+		if weights[id] < 1 {
+			small = append(small, id)
+		} else {
+			large = append(large, id)
+		}
+	}
+	for len(small) > 0 && len(large) > 0 {
+		l, g := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+		s.prob[l], s.alias[l] = int(weights[l]*probBits), g
+		weights[g] -= 1 - weights[l]
+		if weights[g] < 1 {
+			large = large[:len(large)-1]
+			small = append(small, g)
+		}
+	}
+	// the rest have the probability 1 up to the rounding errors.
+	for _, id := range append(small, large...) {
+		s.prob[id], s.alias[id] = probBits, id
+	}
+	return s
+}
+
+// Sample returns the word id drawn by rnd.
+func (s *Sampler) Sample(rnd *modelutil.Random) int {
+	id := rnd.Next(len(s.prob))
+	if rnd.Next(probBits) < s.prob[id] {
+		return id
+	}
+	return s.alias[id]
+}
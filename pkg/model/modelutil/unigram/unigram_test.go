@@ -0,0 +1,51 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package unigram
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+)
+
+func TestSample(t *testing.T) {
+	dic := dictionary.New()
+	for i := 0; i < 8; i++ {
+		dic.Add("a")
+	}
+	dic.Add("b", "b", "c")
+
+	for _, power := range []float64{1, 0.75, 0} {
+		s := New(dic, power)
+		rnd := modelutil.NewRandom(1)
+		counts := make([]int, dic.Len())
+		n := 100000
+		for i := 0; i < n; i++ {
+			counts[s.Sample(rnd)]++
+		}
+		var total float64
+		for id := 0; id < dic.Len(); id++ {
+			total += math.Pow(float64(dic.IDFreq(id)), power)
+		}
+		for id := 0; id < dic.Len(); id++ {
+			expect := math.Pow(float64(dic.IDFreq(id)), power) / total
+			assert.InDelta(t, expect, float64(counts[id])/float64(n), 0.01)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes an invalid value for a field of options.
+type FieldError struct {
+	Field  string
+	Value  interface{}
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s=%v: %s", e.Field, e.Value, e.Reason)
+}
+
+// Errors collects all FieldErrors, in order to report the problems at once.
+type Errors []*FieldError
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = "  - " + err.Error()
+	}
+	return fmt.Sprintf("invalid options:\n%s", strings.Join(msgs, "\n"))
+}
+
+type Validator struct {
+	errs Errors
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+// Check records the FieldError unless ok.
+func (v *Validator) Check(ok bool, field string, value interface{}, reason string) {
+	if !ok {
+		v.errs = append(v.errs, &FieldError{
+			Field:  field,
+			Value:  value,
+			Reason: reason,
+		})
+	}
+}
+
+func (v *Validator) Positive(field string, value int) {
+	v.Check(value > 0, field, value, "must be > 0")
+}
+
+func (v *Validator) NonNegative(field string, value int) {
+	v.Check(value >= 0, field, value, "must be >= 0")
+}
+
+func (v *Validator) PositiveFloat(field string, value float64) {
+	v.Check(value > 0, field, value, "must be > 0")
+}
+
+func (v *Validator) NonNegativeFloat(field string, value float64) {
+	v.Check(value >= 0, field, value, "must be >= 0")
+}
+
+// Range checks that value is in [min, max].
+func (v *Validator) Range(field string, value, min, max float64) {
+	v.Check(min <= value && value <= max, field, value, fmt.Sprintf("must be in [%v, %v]", min, max))
+}
+
+func (v *Validator) OneOf(field string, value string, candidates ...string) {
+	for _, c := range candidates {
+		if value == c {
+			return
+		}
+	}
+	v.Check(false, field, value, fmt.Sprintf("must be one of %s", strings.Join(candidates, "|")))
+}
+
+// Err returns Errors if any check fails, otherwise nil.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
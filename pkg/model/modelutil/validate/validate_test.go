@@ -0,0 +1,42 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+	v := New()
+	v.Positive("Dim", 10)
+	v.OneOf("ModelType", "cbow", "cbow", "skipgram")
+	assert.NoError(t, v.Err())
+
+	v.Positive("Dim", 0)
+	v.NonNegative("MaxDepth", -1)
+	v.Range("WarmupRatio", 1.5, 0, 1)
+	v.OneOf("ModelType", "invalid", "cbow", "skipgram")
+	err := v.Err()
+	assert.Error(t, err)
+
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, 4, len(errs))
+	assert.Equal(t, "Dim", errs[0].Field)
+	assert.Equal(t, "ModelType=invalid: must be one of cbow|skipgram", errs[3].Error())
+}
@@ -22,30 +22,163 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/embedding"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
 	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
 	"github.com/ynqa/wego/pkg/util/verbose"
 )
 
 func InvalidTypeError(typ Type) error {
-	return errors.Errorf("invalid vector type: %s not in %s|%s", typ, Single, Agg)
+	return errors.Errorf("invalid vector type: %s not in %s|%s|%s|%s|%s", typ, Word, Context, Sum, Average, Concat)
+}
+
+func InvalidFormatError(format Format) error {
+	return embedding.InvalidFormatError(format)
+}
+
+// NotApplicableError is returned when the model has no vectors for typ.
+func NotApplicableError(typ Type, reason string) error {
+	return errors.Errorf("vector type %s doesn't apply: %s", typ, reason)
 }
 
 type Type = string
 
 const (
+	// Word is the input (word) vectors.
+	Word Type = "word"
+	// Context is the output (context) vectors on the same vocabulary as the words.
+	Context Type = "context"
+	// Sum, Average and Concat combine the word and the context vectors.
+	Sum     Type = "sum"
+	Average Type = "average"
+	Concat  Type = "concat"
+
+	// Single and Agg are the former names of Word and Sum.
 	Single Type = "single"
 	Agg    Type = "agg"
 )
 
-func Save(f io.Writer, dic *dictionary.Dictionary, mat *matrix.Matrix, verbose *verbose.Verbose, logBatch int) error {
+// Format is the file format to save the vectors, which is the same as embedding.Format.
+type Format = embedding.Format
+
+const (
+	Text   = embedding.Text
+	Vec    = embedding.Vec
+	Binary = embedding.Binary
+)
+
+// FormatOf returns the format by the extension of path as embedding.FormatOf.
+func FormatOf(path string) Format {
+	return embedding.FormatOf(path)
+}
+
+// ValidateFormat returns the error if format is not supported.
+func ValidateFormat(format Format) error {
+	return embedding.ValidateFormat(format)
+}
+
+// Canonical returns typ with the former names replaced.
+func Canonical(typ Type) Type {
+	switch typ {
+	case Single:
+		return Word
+	case Agg:
+		return Sum
+	default:
+		return typ
+	}
+}
+
+// UsesContext reports whether typ requires the context vectors.
+func UsesContext(typ Type) bool {
+	switch Canonical(typ) {
+	case Context, Sum, Average, Concat:
+		return true
+	default:
+		return false
+	}
+}
+
+// Compose builds the n vectors of typ from the word and the context vectors of each row.
+// context is nil if the model has no context vectors on the vocabulary of the words,
+// and then the types which require them fail with NotApplicableError for reason.
+func Compose[T num.Float](typ Type, n int, word, context func(int) []T, reason string) (*matrix.MatrixOf[T], error) {
+	typ = Canonical(typ)
+	switch typ {
+	case Word, Context, Sum, Average, Concat:
+	default:
+		return nil, InvalidTypeError(typ)
+	}
+	if UsesContext(typ) && context == nil {
+		return nil, NotApplicableError(typ, reason)
+	}
+	if n == 0 {
+		return matrix.New[T](0, 0, func(int, []T) {}), nil
+	}
+
+	wdim := len(word(0))
+	var cdim int
+	if context != nil {
+		cdim = len(context(0))
+	}
+	if (typ == Sum || typ == Average) && wdim != cdim {
+		return nil, NotApplicableError(typ, fmt.Sprintf("dimension of word and context vectors are different: %d, %d", wdim, cdim))
+	}
+
+	switch typ {
+	case Context:
+		return matrix.New(n, cdim, func(row int, vec []T) {
+			copy(vec, context(row))
+		}), nil
+	case Sum, Average:
+		scale := T(1)
+		if typ == Average {
+			scale = 0.5
+		}
+		return matrix.New(n, wdim, func(row int, vec []T) {
+			copy(vec, word(row))
+			vecmath.Axpy(1, context(row), vec)
+			vecmath.Scale(scale, vec)
+		}), nil
+	case Concat:
+		return matrix.New(n, wdim+cdim, func(row int, vec []T) {
+			copy(vec[:wdim], word(row))
+			copy(vec[wdim:], context(row))
+		}), nil
+	default:
+		return matrix.New(n, wdim, func(row int, vec []T) {
+			copy(vec, word(row))
+		}), nil
+	}
+}
+
+// Save writes the words of dic and their vectors of mat into f in format.
+func Save[T num.Float](f io.Writer, dic *dictionary.Dictionary, mat *matrix.MatrixOf[T], format Format, verbose *verbose.Verbose, logBatch int) error {
 	if dic.Len() != mat.Row() {
 		return fmt.Errorf("different for length of dic and row of matrix: %d, %d", dic.Len(), mat.Row())
 	}
+	switch format {
+	case Text:
+		return saveText(f, dic, mat, false, verbose, logBatch)
+	case Vec:
+		return saveText(f, dic, mat, true, verbose, logBatch)
+	case Binary:
+		return saveBinary(f, dic, mat, verbose, logBatch)
+	default:
+		return InvalidFormatError(format)
+	}
+}
+
+func saveText[T num.Float](f io.Writer, dic *dictionary.Dictionary, mat *matrix.MatrixOf[T], header bool, verbose *verbose.Verbose, logBatch int) error {
 	writer := bufio.NewWriter(f)
 	defer writer.Flush()
 
 	var buf bytes.Buffer
+	if header {
+		fmt.Fprintf(&buf, "%d %d\n", dic.Len(), mat.Col())
+	}
 	clk := clock.New()
 	for i := 0; i < dic.Len(); i++ {
 		word, _ := dic.Word(i)
@@ -66,3 +199,26 @@ func Save(f io.Writer, dic *dictionary.Dictionary, mat *matrix.Matrix, verbose *
 	})
 	return nil
 }
+
+func saveBinary[T num.Float](f io.Writer, dic *dictionary.Dictionary, mat *matrix.MatrixOf[T], verbose *verbose.Verbose, logBatch int) error {
+	writer := bufio.NewWriter(f)
+	if err := embedding.WriteBinaryHeader(writer, dic.Len(), mat.Col()); err != nil {
+		return err
+	}
+	clk := clock.New()
+	for i := 0; i < dic.Len(); i++ {
+		word, _ := dic.Word(i)
+		if err := embedding.WriteBinary(writer, word, mat.Slice(i)); err != nil {
+			return err
+		}
+		verbose.Do(func() {
+			if i%logBatch == 0 {
+				fmt.Printf("saved %d words %v\r", i, clk.AllElapsed())
+			}
+		})
+	}
+	verbose.Do(func() {
+		fmt.Printf("saved %d words %v\r\n", dic.Len(), clk.AllElapsed())
+	})
+	return writer.Flush()
+}
@@ -0,0 +1,116 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vector
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/dictionary"
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+func TestCompose(t *testing.T) {
+	word := [][]float64{{1, 2}, {3, 4}}
+	context := [][]float64{{5, 6}, {7, 8}}
+	wordFn := func(i int) []float64 { return word[i] }
+	contextFn := func(i int) []float64 { return context[i] }
+
+	testCases := []struct {
+		typ    Type
+		expect [][]float64
+	}{
+		{typ: Word, expect: [][]float64{{1, 2}, {3, 4}}},
+		{typ: Single, expect: [][]float64{{1, 2}, {3, 4}}},
+		{typ: Context, expect: [][]float64{{5, 6}, {7, 8}}},
+		{typ: Sum, expect: [][]float64{{6, 8}, {10, 12}}},
+		{typ: Agg, expect: [][]float64{{6, 8}, {10, 12}}},
+		{typ: Average, expect: [][]float64{{3, 4}, {5, 6}}},
+		{typ: Concat, expect: [][]float64{{1, 2, 5, 6}, {3, 4, 7, 8}}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.typ, func(t *testing.T) {
+			mat, err := Compose(tc.typ, 2, wordFn, contextFn, "")
+			assert.NoError(t, err)
+			assert.Equal(t, 2, mat.Row())
+			for i, expect := range tc.expect {
+				assert.Equal(t, expect, mat.Slice(i))
+			}
+		})
+	}
+}
+
+func TestComposeError(t *testing.T) {
+	wordFn := func(int) []float64 { return []float64{1, 2} }
+
+	_, err := Compose("unknown", 1, wordFn, wordFn, "")
+	assert.Error(t, err)
+
+	mat, err := Compose(Word, 1, wordFn, nil, "no context")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mat.Col())
+	for _, typ := range []Type{Context, Sum, Average, Concat} {
+		_, err := Compose(typ, 1, wordFn, nil, "no context")
+		assert.EqualError(t, err, "vector type "+typ+" doesn't apply: no context")
+	}
+
+	contextFn := func(int) []float64 { return []float64{1, 2, 3} }
+	_, err = Compose(Sum, 1, wordFn, contextFn, "")
+	assert.Error(t, err)
+	mat, err = Compose(Concat, 1, wordFn, contextFn, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, mat.Col())
+}
+
+func TestSave(t *testing.T) {
+	dic := dictionary.New()
+	dic.Add("apple", "banana")
+	mat := matrix.New(2, 2, func(row int, vec []float64) {
+		vec[0], vec[1] = float64(row), 0.5
+	})
+
+	var text bytes.Buffer
+	assert.NoError(t, Save(&text, dic, mat, Text, verbose.New(false), 1))
+	assert.Equal(t, "apple 0.000000 0.500000 \nbanana 1.000000 0.500000 \n", text.String())
+
+	var vec bytes.Buffer
+	assert.NoError(t, Save(&vec, dic, mat, Vec, verbose.New(false), 1))
+	assert.Equal(t, "2 2\n"+text.String(), vec.String())
+	embs, err := embedding.Load(&vec)
+	assert.NoError(t, err)
+	assert.Len(t, embs, 2)
+
+	var bin bytes.Buffer
+	assert.NoError(t, Save(&bin, dic, mat, Binary, verbose.New(false), 1))
+	embs, err = embedding.LoadBinary(&bin)
+	assert.NoError(t, err)
+	assert.Len(t, embs, 2)
+	assert.Equal(t, "banana", embs[1].Word)
+	assert.Equal(t, []float64{1, 0.5}, embs[1].Vector)
+
+	assert.Error(t, Save(&bin, dic, mat, "csv", verbose.New(false), 1))
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, Binary, FormatOf("vectors.bin"))
+	assert.Equal(t, Binary, FormatOf("vectors.BIN"))
+	assert.Equal(t, Vec, FormatOf("vectors.vec"))
+	assert.Equal(t, Text, FormatOf("vectors.txt"))
+	assert.Equal(t, Text, FormatOf("vectors"))
+}
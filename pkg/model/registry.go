@@ -0,0 +1,112 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// Factory creates a model from its options, which are bound to the flags of a command.
+type Factory interface {
+	// LoadForCmd binds the options to the flags of cmd with the default values.
+	LoadForCmd(cmd *cobra.Command)
+	// New creates the model for the options, which are the default ones unless they're
+	// changed by the flags.
+	New() (Model, error)
+	// Options returns the current options, e.g. to record them with the vectors.
+	Options() interface{}
+}
+
+// Registration is an entry of the registry.
+type Registration struct {
+	// Name is the identifier of the model, which is used as the command name.
+	Name string
+	// Short is the one-line description of the model.
+	Short string
+	// Factory returns a new factory with the default options.
+	Factory func() Factory
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Registration)
+)
+
+// Register makes the model available by its name, e.g. in `wego train --model <name>`.
+// It's supposed to be called in init of the package of the model, and panics if the name
+// is empty or already registered.
+func Register(reg Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if reg.Name == "" || reg.Factory == nil {
+		panic("model: Register requires the name and the factory")
+	}
+	if _, ok := registry[reg.Name]; ok {
+		panic(errors.Errorf("model: Register called twice for %s", reg.Name))
+	}
+	registry[reg.Name] = reg
+}
+
+// Lookup returns the registration of the model by name.
+func Lookup(name string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	reg, ok := registry[name]
+	return reg, ok
+}
+
+// Names returns the names of the registered models in sorted order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type factory[O any] struct {
+	opts   O
+	load   func(*cobra.Command, *O)
+	create func(O) (Model, error)
+}
+
+// NewFactory returns the factory from the DefaultOptions, LoadForCmd and NewForOptions
+// of the model package.
+func NewFactory[O any](defaults func() O, load func(*cobra.Command, *O), create func(O) (Model, error)) Factory {
+	return &factory[O]{
+		opts:   defaults(),
+		load:   load,
+		create: create,
+	}
+}
+
+func (f *factory[O]) LoadForCmd(cmd *cobra.Command) {
+	f.load(cmd, &f.opts)
+}
+
+func (f *factory[O]) New() (Model, error) {
+	return f.create(f.opts)
+}
+
+func (f *factory[O]) Options() interface{} {
+	return f.opts
+}
@@ -0,0 +1,100 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+)
+
+type fakeOptions struct {
+	Dim int
+}
+
+// fake implements only Model, as the models out of wego.
+type fake struct {
+	opts fakeOptions
+}
+
+func (f *fake) Train(context.Context, io.ReadSeeker) error { return nil }
+func (f *fake) Loss() []float64                            { return nil }
+
+// fakeVectors has the small values which are rounded by the text format.
+var fakeVectors = [][]float64{{1, 0.5}, {-1.2345678e-4, 3e-7}}
+
+func (f *fake) WordVector(vector.Type) (*matrix.Matrix, error) {
+	return matrix.New(len(fakeVectors), 2, func(row int, vec []float64) {
+		copy(vec, fakeVectors[row])
+	}), nil
+}
+
+func (f *fake) Save(w io.Writer, _ vector.Type) error {
+	for i, word := range []string{"apple", "banana"} {
+		if _, err := fmt.Fprintf(w, "%s %f %f \n", word, fakeVectors[i][0], fakeVectors[i][1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	reg := Registration{
+		Name:  "fake",
+		Short: "fake model",
+		Factory: func() Factory {
+			return NewFactory(
+				func() fakeOptions {
+					return fakeOptions{Dim: 10}
+				},
+				func(cmd *cobra.Command, opts *fakeOptions) {
+					cmd.Flags().IntVar(&opts.Dim, "dim", 10, "dimension")
+				},
+				func(opts fakeOptions) (Model, error) {
+					return &fake{opts: opts}, nil
+				},
+			)
+		},
+	}
+	Register(reg)
+	assert.Panics(t, func() { Register(reg) })
+	assert.Contains(t, Names(), "fake")
+
+	got, ok := Lookup("fake")
+	assert.True(t, ok)
+	assert.Equal(t, "fake model", got.Short)
+	_, ok = Lookup("unknown")
+	assert.False(t, ok)
+
+	// the default options without the flags.
+	m, err := got.Factory().New()
+	assert.NoError(t, err)
+	assert.Equal(t, 10, m.(*fake).opts.Dim)
+
+	factory := got.Factory()
+	cmd := &cobra.Command{}
+	factory.LoadForCmd(cmd)
+	assert.NoError(t, cmd.ParseFlags([]string{"--dim", "20"}))
+	m, err = factory.New()
+	assert.NoError(t, err)
+	assert.Equal(t, 20, m.(*fake).opts.Dim)
+}
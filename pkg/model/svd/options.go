@@ -0,0 +1,217 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svd
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/model/modelutil/validate"
+)
+
+var (
+	defaultCountType   = co.Increment
+	defaultDim         = 10
+	defaultDocInMemory = false
+	defaultEigenWeight = 0.5
+	defaultGoroutines  = runtime.NumCPU()
+	defaultLogBatch    = 100000
+	defaultMaxCount    = -1
+	defaultMinCount    = 5
+	defaultOversample  = 10
+	defaultPowerIter   = 2
+	defaultSeed        = int64(1)
+	defaultShift       = 1.0
+	defaultSmooth      = 0.75
+	defaultToLower     = false
+	defaultVerbose     = false
+	defaultWindow      = 5
+)
+
+type Options struct {
+	CountType   co.CountType
+	Dim         int
+	DocInMemory bool
+	EigenWeight float64
+	Goroutines  int
+	LogBatch    int
+	MaxCount    int
+	MinCount    int
+	Oversample  int
+	PowerIter   int
+	Seed        int64
+	Shift       float64
+	Smooth      float64
+	ToLower     bool
+	Verbose     bool
+	Window      int
+}
+
+func DefaultOptions() Options {
+	return Options{
+		CountType:   defaultCountType,
+		Dim:         defaultDim,
+		DocInMemory: defaultDocInMemory,
+		EigenWeight: defaultEigenWeight,
+		Goroutines:  defaultGoroutines,
+		LogBatch:    defaultLogBatch,
+		MaxCount:    defaultMaxCount,
+		MinCount:    defaultMinCount,
+		Oversample:  defaultOversample,
+		PowerIter:   defaultPowerIter,
+		Seed:        defaultSeed,
+		Shift:       defaultShift,
+		Smooth:      defaultSmooth,
+		ToLower:     defaultToLower,
+		Verbose:     defaultVerbose,
+		Window:      defaultWindow,
+	}
+}
+
+// Validate reports all invalid values of Options at once.
+func (opts Options) Validate() error {
+	v := validate.New()
+	v.OneOf("CountType", opts.CountType, co.Increment, co.Proximity)
+	v.Positive("Dim", opts.Dim)
+	v.Range("EigenWeight", opts.EigenWeight, 0, 1)
+	v.Positive("Goroutines", opts.Goroutines)
+	v.Positive("LogBatch", opts.LogBatch)
+	v.NonNegative("Oversample", opts.Oversample)
+	v.NonNegative("PowerIter", opts.PowerIter)
+	v.Check(opts.Shift >= 1, "Shift", opts.Shift, "must be >= 1")
+	v.NonNegativeFloat("Smooth", opts.Smooth)
+	v.Positive("Window", opts.Window)
+	return v.Err()
+}
+
+func LoadForCmd(cmd *cobra.Command, opts *Options) {
+	cmd.Flags().StringVar(&opts.CountType, "cnt", defaultCountType, fmt.Sprintf("count type for co-occurrence words. One of %s|%s", co.Increment, co.Proximity))
+	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector (> 0)")
+	cmd.Flags().Float64Var(&opts.EigenWeight, "eigen-weight", defaultEigenWeight, "exponent p to weight singular values for word vector U*S^p, e.g. 0, 0.5 or 1 (in [0, 1])")
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine (> 0)")
+	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", defaultDocInMemory, "whether to store the doc in memory")
+	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words (> 0)")
+	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words")
+	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words")
+	cmd.Flags().IntVar(&opts.Oversample, "oversample", defaultOversample, "number of extra random vectors for randomized SVD (>= 0)")
+	cmd.Flags().IntVar(&opts.PowerIter, "power-iter", defaultPowerIter, "number of power iterations for randomized SVD (>= 0)")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", defaultSeed, "random seed for randomized SVD")
+	cmd.Flags().Float64Var(&opts.Shift, "shift", defaultShift, "shift k of PPMI, max(PMI - log k, 0) (>= 1)")
+	cmd.Flags().Float64Var(&opts.Smooth, "smooth", defaultSmooth, "smoothing value for context frequencies in PMI (>= 0)")
+	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on corpus convert to lowercase or not")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
+	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size (> 0)")
+}
+
+type ModelOption func(*Options)
+
+func CountType(typ co.CountType) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.CountType = typ
+	})
+}
+
+func Dim(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Dim = v
+	})
+}
+
+func DocInMemory() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.DocInMemory = true
+	})
+}
+
+func EigenWeight(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.EigenWeight = v
+	})
+}
+
+func Goroutines(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Goroutines = v
+	})
+}
+
+func LogBatch(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LogBatch = v
+	})
+}
+
+func MaxCount(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxCount = v
+	})
+}
+
+func MinCount(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MinCount = v
+	})
+}
+
+func Oversample(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Oversample = v
+	})
+}
+
+func PowerIter(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.PowerIter = v
+	})
+}
+
+func Seed(v int64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Seed = v
+	})
+}
+
+func Shift(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Shift = v
+	})
+}
+
+func Smooth(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Smooth = v
+	})
+}
+
+func ToLower() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.ToLower = true
+	})
+}
+
+func Verbose() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Verbose = true
+	})
+}
+
+func Window(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Window = v
+	})
+}
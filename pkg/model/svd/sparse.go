@@ -0,0 +1,100 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svd
+
+import (
+	"sort"
+
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/model/modelutil/pmi"
+)
+
+// sparse is the square matrix in the compressed sparse row format.
+type sparse struct {
+	n       int
+	indptr  []int
+	indices []int
+	values  []float64
+}
+
+type entry struct {
+	row, col int
+	value    float64
+}
+
+// newPPMI builds the shifted PPMI matrix from the co-occurrence, which is counted for
+// the unordered pairs, so that both (w, c) and (c, w) are filled.
+func newPPMI(n int, cooc *co.Cooccurrence, p *pmi.PMI, shift float64) *sparse {
+	em := cooc.EncodedMatrix()
+	entries := make([]entry, 0, len(em)*2)
+	add := func(row, col int, f float64) {
+		if v := p.Positive(row, col, f, shift); v > 0 {
+			entries = append(entries, entry{row: row, col: col, value: v})
+		}
+	}
+	for enc, f := range em {
+		u1, u2 := encode.DecodeBigram(enc)
+		l1, l2 := int(u1), int(u2)
+		add(l1, l2, f)
+		if l1 != l2 {
+			add(l2, l1, f)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].row != entries[j].row {
+			return entries[i].row < entries[j].row
+		}
+		return entries[i].col < entries[j].col
+	})
+
+	a := &sparse{
+		n:       n,
+		indptr:  make([]int, n+1),
+		indices: make([]int, len(entries)),
+		values:  make([]float64, len(entries)),
+	}
+	for i, e := range entries {
+		a.indptr[e.row+1]++
+		a.indices[i], a.values[i] = e.col, e.value
+	}
+	for i := 0; i < n; i++ {
+		a.indptr[i+1] += a.indptr[i]
+	}
+	return a
+}
+
+// mulVec computes y = a x.
+func (a *sparse) mulVec(x, y []float64) {
+	for i := 0; i < a.n; i++ {
+		var s float64
+		for k := a.indptr[i]; k < a.indptr[i+1]; k++ {
+			s += a.values[k] * x[a.indices[k]]
+		}
+		y[i] = s
+	}
+}
+
+// mulTransVec computes y = aᵀ x.
+func (a *sparse) mulTransVec(x, y []float64) {
+	for i := range y {
+		y[i] = 0
+	}
+	for i := 0; i < a.n; i++ {
+		for k := a.indptr[i]; k < a.indptr[i+1]; k++ {
+			y[a.indices[k]] += a.values[k] * x[i]
+		}
+	}
+}
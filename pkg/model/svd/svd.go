@@ -0,0 +1,233 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sync"
+
+	"golang.org/x/sync/semaphore"
+
+	"github.com/ynqa/wego/pkg/corpus"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/pmi"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/linalg"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+// svd factorizes the PPMI matrix by the randomized truncated SVD (Halko et al. 2011),
+// and weights the singular vectors by the singular values to the power of EigenWeight
+// (Levy et al. 2015).
+type svd struct {
+	opts Options
+
+	corpus corpus.Corpus
+
+	// word and ctx store U*S^p and V*S^p.
+	word *matrix.Matrix
+	ctx  *matrix.Matrix
+
+	verbose *verbose.Verbose
+}
+
+func New(opts ...ModelOption) (model.Model, error) {
+	options := DefaultOptions()
+	for _, fn := range opts {
+		fn(&options)
+	}
+
+	return NewForOptions(options)
+}
+
+func NewForOptions(opts Options) (model.Model, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	v := verbose.New(opts.Verbose)
+	return &svd{
+		opts: opts,
+
+		verbose: v,
+	}, nil
+}
+
+func (s *svd) Train(ctx context.Context, r io.ReadSeeker) error {
+	c, err := modelutil.LoadCorpus(
+		ctx, r,
+		corpus.Key{
+			InMemory: s.opts.DocInMemory,
+			ToLower:  s.opts.ToLower,
+			MaxCount: s.opts.MaxCount,
+			MinCount: s.opts.MinCount,
+		},
+		&corpus.WithCooccurrence{
+			CountType: s.opts.CountType,
+			Window:    s.opts.Window,
+		},
+		s.verbose, s.opts.LogBatch,
+	)
+	if err != nil {
+		return err
+	}
+	s.corpus = c
+
+	dic, clk := s.corpus.Dictionary(), clock.New()
+	a := newPPMI(
+		dic.Len(),
+		s.corpus.Cooccurrence(),
+		pmi.New(dic, s.corpus.Len(), s.opts.Smooth),
+		s.opts.Shift,
+	)
+	s.verbose.Do(func() {
+		fmt.Printf("build %d ppmi entries %v\r\n", len(a.values), clk.AllElapsed())
+	})
+
+	return s.factorize(ctx, a)
+}
+
+func (s *svd) factorize(ctx context.Context, a *sparse) error {
+	clk := clock.New()
+	n, dim := a.n, s.opts.Dim
+	l := dim + s.opts.Oversample
+	if l > n {
+		l = n
+	}
+
+	// the columns of the n×l matrices are stored as rows.
+	rnd := rand.New(rand.NewSource(s.opts.Seed))
+	omega := newRows(l, n)
+	for _, row := range omega {
+		for i := range row {
+			row[i] = rnd.NormFloat64()
+		}
+	}
+	q := newRows(l, n)
+	if err := s.parallel(ctx, l, func(j int) {
+		a.mulVec(omega[j], q[j])
+	}); err != nil {
+		return err
+	}
+	linalg.Orthonormalize(q)
+
+	z := omega
+	for it := 0; it < s.opts.PowerIter; it++ {
+		if err := s.parallel(ctx, l, func(j int) {
+			a.mulTransVec(q[j], z[j])
+		}); err != nil {
+			return err
+		}
+		linalg.Orthonormalize(z)
+		if err := s.parallel(ctx, l, func(j int) {
+			a.mulVec(z[j], q[j])
+		}); err != nil {
+			return err
+		}
+		linalg.Orthonormalize(q)
+	}
+
+	// b = qᵀ a, stored as the rows of aᵀ q.
+	b := z
+	if err := s.parallel(ctx, l, func(j int) {
+		a.mulTransVec(q[j], b[j])
+	}); err != nil {
+		return err
+	}
+	sv, ub, vb := linalg.SVD(b)
+
+	s.word = matrix.New(n, dim, func(int, []float64) {})
+	s.ctx = matrix.New(n, dim, func(int, []float64) {})
+	for m := 0; m < dim && m < l; m++ {
+		weight := math.Pow(sv[m], s.opts.EigenWeight)
+		// the m-th left singular vector of a is q ub[m].
+		u := make([]float64, n)
+		for j := 0; j < l; j++ {
+			vecmath.Axpy(ub[m][j], q[j], u)
+		}
+		for i := 0; i < n; i++ {
+			s.word.Slice(i)[m] = u[i] * weight
+			s.ctx.Slice(i)[m] = vb[m][i] * weight
+		}
+	}
+	s.verbose.Do(func() {
+		fmt.Printf("factorized %dx%d matrix into %d dims %v\r\n", n, n, dim, clk.AllElapsed())
+	})
+	return nil
+}
+
+func newRows(rows, cols int) [][]float64 {
+	res := make([][]float64, rows)
+	for i := range res {
+		res[i] = make([]float64, cols)
+	}
+	return res
+}
+
+func (s *svd) parallel(ctx context.Context, size int, fn func(int)) error {
+	sem := semaphore.NewWeighted(int64(s.opts.Goroutines))
+	wg := &sync.WaitGroup{}
+	for i := 0; i < size; i++ {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			wg.Wait()
+			return err
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer sem.Release(1)
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+func (s *svd) Save(f io.Writer, typ vector.Type) error {
+	return s.SaveFormat(f, typ, vector.Text)
+}
+
+func (s *svd) SaveFormat(f io.Writer, typ vector.Type, format vector.Format) error {
+	mat, err := s.WordVector(typ)
+	if err != nil {
+		return err
+	}
+	return vector.Save(f, s.corpus.Dictionary(), mat, format, s.verbose, s.opts.LogBatch)
+}
+
+// Loss returns no history since the factorization doesn't run epochs.
+func (s *svd) Loss() []float64 {
+	return nil
+}
+
+// Stats has no epoch times since the factorization doesn't run epochs.
+func (s *svd) Stats() model.Stats {
+	return modelutil.CorpusStats(s.corpus, s.opts.MaxCount, s.opts.MinCount, nil)
+}
+
+// WordVector returns U S^p for the words and V S^p for the contexts.
+func (s *svd) WordVector(typ vector.Type) (*matrix.Matrix, error) {
+	if s.word == nil {
+		return nil, model.ErrNotTrained
+	}
+	return vector.Compose(typ, s.word.Row(), s.word.Slice, s.ctx.Slice, "")
+}
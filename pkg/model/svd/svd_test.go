@@ -0,0 +1,84 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+func TestFactorize(t *testing.T) {
+	// rank 2 matrix: a = x yᵀ + z wᵀ
+	x, y := []float64{1, 2, 0, 1, 3}, []float64{2, 0, 1, 1, 0}
+	z, w := []float64{0, 1, 1, 0, 2}, []float64{1, 1, 0, 3, 1}
+	n := len(x)
+	a := &sparse{n: n, indptr: make([]int, n+1)}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if v := x[i]*y[j] + z[i]*w[j]; v != 0 {
+				a.indices = append(a.indices, j)
+				a.values = append(a.values, v)
+			}
+		}
+		a.indptr[i+1] = len(a.values)
+	}
+
+	opts := DefaultOptions()
+	opts.Dim = 2
+	m, err := NewForOptions(opts)
+	assert.NoError(t, err)
+	s := m.(*svd)
+	assert.NoError(t, s.factorize(context.Background(), a))
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			// U S^0.5 (V S^0.5)ᵀ = U S Vᵀ
+			assert.InDelta(t, x[i]*y[j]+z[i]*w[j], vecmath.Dot(s.word.Slice(i), s.ctx.Slice(j)), 1e-6)
+		}
+	}
+}
+
+func TestTrain(t *testing.T) {
+	var buf bytes.Buffer
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&buf, "w%d ", rnd.Intn(300))
+	}
+	doc := buf.Bytes()
+
+	train := func() [][]float64 {
+		opts := DefaultOptions()
+		opts.Goroutines = 2
+		m, err := NewForOptions(opts)
+		assert.NoError(t, err)
+		assert.NoError(t, m.Train(context.Background(), bytes.NewReader(doc)))
+		mat, err := m.WordVector(vector.Word)
+		assert.NoError(t, err)
+		res := make([][]float64, mat.Row())
+		for i := range res {
+			res[i] = mat.Slice(i)
+		}
+		return res
+	}
+	// deterministic for the same seed.
+	assert.Equal(t, train(), train())
+}
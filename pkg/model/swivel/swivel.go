@@ -0,0 +1,359 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swivel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ynqa/wego/pkg/corpus"
+	co "github.com/ynqa/wego/pkg/corpus/cooccurrence"
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/loss"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/pmi"
+	"github.com/ynqa/wego/pkg/model/modelutil/progress"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/shuffle"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+// item is the observed cell of the co-occurrence matrix.
+type item struct {
+	pmi  float64
+	conf float64
+}
+
+type swivel[T num.Float] struct {
+	opts Options
+
+	corpus corpus.Corpus
+
+	// param stores the row (word) vectors in [0, n) and the column (context) vectors in [n, 2n).
+	param      *matrix.MatrixOf[T]
+	gradsq     *matrix.MatrixOf[T]
+	shards     [][]int
+	items      map[uint64]item
+	pmi        *pmi.PMI
+	schedule   schedule.Schedule
+	progress   *progress.Progress
+	loss       *loss.Loss
+	epochTimes []time.Duration
+
+	verbose *verbose.Verbose
+}
+
+func New(opts ...ModelOption) (model.Model, error) {
+	options := DefaultOptions()
+	for _, fn := range opts {
+		fn(&options)
+	}
+
+	return NewForOptions(options)
+}
+
+func NewForOptions(opts Options) (model.Model, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Precision == matrix.Float32 {
+		return newSwivel[float32](opts), nil
+	}
+	return newSwivel[float64](opts), nil
+}
+
+func newSwivel[T num.Float](opts Options) *swivel[T] {
+	v := verbose.New(opts.Verbose)
+	return &swivel[T]{
+		opts: opts,
+
+		loss: loss.New(),
+
+		verbose: v,
+	}
+}
+
+func (s *swivel[T]) Train(ctx context.Context, r io.ReadSeeker) error {
+	if s.opts.TimeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.TimeBudget)
+		defer cancel()
+	}
+
+	c, err := modelutil.LoadCorpus(
+		ctx, r,
+		corpus.Key{
+			InMemory: s.opts.DocInMemory,
+			ToLower:  s.opts.ToLower,
+			MaxCount: s.opts.MaxCount,
+			MinCount: s.opts.MinCount,
+		},
+		&corpus.WithCooccurrence{
+			CountType: s.opts.CountType,
+			Window:    s.opts.Window,
+		},
+		s.verbose, s.opts.LogBatch,
+	)
+	if err != nil {
+		return err
+	}
+	s.corpus = c
+
+	dic, dim := s.corpus.Dictionary(), s.opts.Dim
+
+	rnd := rand.New(rand.NewSource(s.opts.Seed))
+	s.param = matrix.New(
+		dic.Len()*2,
+		dim,
+		func(_ int, vec []T) {
+			for i := 0; i < dim; i++ {
+				vec[i] = T((rnd.Float64() - 0.5) / float64(dim))
+			}
+		},
+	)
+
+	s.gradsq = matrix.New(
+		dic.Len()*2,
+		dim,
+		func(_ int, vec []T) {
+			for i := 0; i < dim; i++ {
+				vec[i] = 1.
+			}
+		},
+	)
+
+	sched, err := schedule.New(s.opts.LRSchedule, schedule.Config{
+		Initlr:      s.opts.Initlr,
+		MinLR:       s.opts.MinLR,
+		Iter:        s.opts.Iter,
+		WarmupRatio: s.opts.WarmupRatio,
+		StepDecay:   s.opts.StepDecay,
+	})
+	if err != nil {
+		return err
+	}
+	s.schedule = sched
+	s.progress = progress.New(dic.Len()*dic.Len()*s.opts.Iter, s.opts.LogBatch)
+
+	s.pmi = pmi.New(dic, s.corpus.Len(), s.opts.Smooth)
+	s.items = s.makeItems(s.corpus.Cooccurrence())
+	s.shards = s.makeShards()
+
+	return s.train(ctx)
+}
+
+// makeItems stores the observed cells in both orientations, since the smoothing of PMI is applied
+// to the column (context) only and makes the matrix asymmetric.
+func (s *swivel[T]) makeItems(cooc *co.Cooccurrence) map[uint64]item {
+	em := cooc.EncodedMatrix()
+	res := make(map[uint64]item, len(em)*2)
+	for enc, f := range em {
+		u1, u2 := encode.DecodeBigram(enc)
+		l1, l2 := int(u1), int(u2)
+		conf := s.opts.ConfidenceBase + s.opts.ConfidenceScale*math.Pow(f, s.opts.ConfidenceExponent)
+		res[cell(l1, l2)] = item{
+			pmi:  s.pmi.Value(l1, l2, f),
+			conf: conf,
+		}
+		res[cell(l2, l1)] = item{
+			pmi:  s.pmi.Value(l2, l1, f),
+			conf: conf,
+		}
+	}
+	return res
+}
+
+// cell returns the key of items for (row, col), which unlike encode.EncodeBigram keeps the order.
+func cell(row, col int) uint64 {
+	return uint64(row) | uint64(col)<<32
+}
+
+// makeShards assigns the words sorted by frequency to the shards in round-robin,
+// so that every block has a similar mix of frequent and rare words.
+func (s *swivel[T]) makeShards() [][]int {
+	dic := s.corpus.Dictionary()
+	ids := make([]int, dic.Len())
+	for i := range ids {
+		ids[i] = i
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return dic.IDFreq(ids[i]) > dic.IDFreq(ids[j])
+	})
+
+	k := (len(ids) + s.opts.ShardSize - 1) / s.opts.ShardSize
+	shards := make([][]int, k)
+	for i, id := range ids {
+		shards[i%k] = append(shards[i%k], id)
+	}
+	return shards
+}
+
+func (s *swivel[T]) train(ctx context.Context) error {
+	k := len(s.shards)
+	blocks := make([][2]int, 0, k*k)
+	for i := 0; i < k; i++ {
+		for j := 0; j < k; j++ {
+			blocks = append(blocks, [2]int{i, j})
+		}
+	}
+
+	for i := 1; i <= s.opts.Iter && ctx.Err() == nil; i++ {
+		clk := s.startEpoch()
+
+		shuffle.Slice(shuffle.Rand(s.opts.Seed, i), blocks)
+		modelutil.RunWorkers(s.opts.Goroutines, modelutil.Jobs(blocks), func(int) func([2]int) {
+			return func(b [2]int) {
+				s.trainPerThread(ctx, s.shards[b[0]], s.shards[b[1]])
+			}
+		})
+
+		s.endEpoch(clk)
+	}
+	return ctx.Err()
+}
+
+func (s *swivel[T]) trainPerThread(
+	ctx context.Context,
+	rows, cols []int,
+) {
+	var (
+		sum float64
+		cnt int
+	)
+	counter := s.progress.NewCounter(s.opts.UpdateLRBatch)
+	lr := s.schedule(s.progress.Ratio())
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			break
+		}
+		for _, col := range cols {
+			sum += s.trainOne(row, col, lr)
+			cnt++
+			if counter.Inc() {
+				lr = s.schedule(s.progress.Ratio())
+			}
+		}
+	}
+	counter.Flush()
+	s.loss.Add(sum, cnt)
+}
+
+// trainOne fits the cell (row, col) by AdaGrad: the squared error weighted by the confidence
+// for the observed co-occurrence, and the soft hinge loss against PMI with the count
+// smoothed to one for the unobserved co-occurrence.
+func (s *swivel[T]) trainOne(row, col int, lr float64) float64 {
+	ctx := col + s.corpus.Dictionary().Len()
+	v1, v2 := s.param.Slice(row), s.param.Slice(ctx)
+	g1, g2 := s.gradsq.Slice(row), s.gradsq.Slice(ctx)
+	dot := float64(vecmath.Dot(v1, v2))
+
+	var loss, g float64
+	if it, ok := s.items[cell(row, col)]; ok {
+		diff := dot - it.pmi
+		loss, g = 0.5*it.conf*diff*diff, it.conf*diff
+	} else {
+		z := dot - s.pmi.Value(row, col, 1)
+		loss, g = softplus(z), 1/(1+math.Exp(-z))
+	}
+
+	d, rate := T(g), T(lr)
+	v2, g1, g2 = v2[:len(v1)], g1[:len(v1)], g2[:len(v1)]
+	for i := range v1 {
+		t1, t2 := d*v2[i], d*v1[i]
+		g1[i] += t1 * t1
+		g2[i] += t2 * t2
+		v1[i] -= rate * t1 / T(math.Sqrt(float64(g1[i])))
+		v2[i] -= rate * t2 / T(math.Sqrt(float64(g2[i])))
+	}
+	return loss
+}
+
+func softplus(z float64) float64 {
+	if z > 0 {
+		return z + math.Log1p(math.Exp(-z))
+	}
+	return math.Log1p(math.Exp(z))
+}
+
+func (s *swivel[T]) startEpoch() *clock.Clock {
+	clk := clock.New()
+	s.progress.StartEpoch(func(trained int64) {
+		s.verbose.Do(func() {
+			fmt.Printf("trained %d cells %v loss %f\r", trained, clk.AllElapsed(), s.loss.Current())
+		})
+	})
+	return clk
+}
+
+func (s *swivel[T]) endEpoch(clk *clock.Clock) {
+	s.loss.Epoch()
+	s.epochTimes = append(s.epochTimes, clk.AllElapsed())
+	s.verbose.Do(func() {
+		fmt.Printf("trained %d cells %v loss %f\r\n", s.progress.Epoch(), clk.AllElapsed(), s.loss.Last())
+	})
+}
+
+func (s *swivel[T]) Save(f io.Writer, typ vector.Type) error {
+	return s.SaveFormat(f, typ, vector.Text)
+}
+
+func (s *swivel[T]) SaveFormat(f io.Writer, typ vector.Type, format vector.Format) error {
+	mat, err := s.wordVector(typ)
+	if err != nil {
+		return err
+	}
+	return vector.Save(f, s.corpus.Dictionary(), mat, format, s.verbose, s.opts.LogBatch)
+}
+
+func (s *swivel[T]) Loss() []float64 {
+	return s.loss.History()
+}
+
+func (s *swivel[T]) Stats() model.Stats {
+	return modelutil.CorpusStats(s.corpus, s.opts.MaxCount, s.opts.MinCount, s.epochTimes)
+}
+
+func (s *swivel[T]) WordVector(typ vector.Type) (*matrix.Matrix, error) {
+	mat, err := s.wordVector(typ)
+	if err != nil {
+		return nil, err
+	}
+	return matrix.Convert[float64](mat), nil
+}
+
+func (s *swivel[T]) wordVector(typ vector.Type) (*matrix.MatrixOf[T], error) {
+	if s.param == nil {
+		return nil, model.ErrNotTrained
+	}
+	n := s.corpus.Dictionary().Len()
+	return vector.Compose(typ, n,
+		s.param.Slice,
+		func(row int) []T {
+			return s.param.Slice(row + n)
+		},
+		"",
+	)
+}
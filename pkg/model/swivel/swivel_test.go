@@ -0,0 +1,68 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package swivel
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/corpus/cooccurrence/encode"
+	"github.com/ynqa/wego/pkg/model/internal/modeltest"
+)
+
+func TestTrain(t *testing.T) {
+	doc := modeltest.Corpus(20000, 500)
+	for _, inMemory := range []bool{true, false} {
+		t.Run(fmt.Sprintf("inMemory=%t", inMemory), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.DocInMemory = inMemory
+			opts.Goroutines = 1
+			opts.Iter = 3
+			// the small shards make the blocks more than one.
+			opts.ShardSize = 32
+			opts.UpdateLRBatch = 100
+			m := newSwivel[float64](opts)
+			loss := modeltest.Train(t, m, doc, opts.Iter, opts.Dim)
+			assert.Less(t, loss[len(loss)-1], loss[0])
+
+			n := m.corpus.Dictionary().Len()
+			assert.Greater(t, len(m.shards), 1)
+			// the unobserved cells are trained by the soft hinge loss.
+			assert.Less(t, len(m.items), n*n)
+		})
+	}
+}
+
+func TestMakeItems(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Goroutines = 1
+	opts.Iter = 1
+	opts.Smooth = 0.75
+	m := newSwivel[float64](opts)
+	modeltest.Train(t, m, modeltest.Corpus(2000, 50), opts.Iter, opts.Dim)
+
+	var asymmetric bool
+	for enc, f := range m.corpus.Cooccurrence().EncodedMatrix() {
+		u1, u2 := encode.DecodeBigram(enc)
+		l1, l2 := int(u1), int(u2)
+		// l1 <= l2 for the encoded bigram.
+		assert.Equal(t, m.pmi.Value(l1, l2, f), m.items[cell(l1, l2)].pmi)
+		assert.Equal(t, m.pmi.Value(l2, l1, f), m.items[cell(l2, l1)].pmi)
+		asymmetric = asymmetric || m.items[cell(l1, l2)].pmi != m.items[cell(l2, l1)].pmi
+	}
+	assert.True(t, asymmetric)
+}
@@ -17,141 +17,270 @@ package word2vec
 import (
 	"github.com/ynqa/wego/pkg/model/modelutil"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
 )
 
-type mod interface {
+type mod[T num.Float] interface {
 	trainOne(
 		doc []int,
 		pos int,
 		lr float64,
-		param *matrix.Matrix,
-		optimizer optimizer,
-	)
+		param *matrix.MatrixOf[T],
+		optimizer optimizer[T],
+		rnd *modelutil.Random,
+	) (float64, int)
+}
+
+// outputShape returns the number of output parameter sets and their dimension for the model.
+func outputShape(opts Options) (int, int) {
+	switch opts.ModelType {
+	case StructuredSkipGram:
+		return newWindow(opts).size(), opts.Dim
+	case CWindow:
+		return 1, newWindow(opts).size() * opts.Dim
+	default:
+		return 1, opts.Dim
+	}
+}
+
+// window is the context window around the center word, which is shrunk randomly
+// for each word unless it's fixed.
+type window struct {
+	left, right int
+	fixed       bool
+}
+
+func newWindow(opts Options) window {
+	left, right := opts.windows()
+	return window{
+		left:  left,
+		right: right,
+		fixed: opts.FixedWindow,
+	}
 }
 
-type skipGram struct {
-	ch     chan []float64
-	window int
+// size returns the number of the relative positions in the full window.
+func (w window) size() int {
+	return w.left + w.right
 }
 
-func newSkipGram(opts Options) mod {
-	ch := make(chan []float64, opts.Goroutines)
+// bounds returns the interval [s, e) of the contexts of pos in the doc of length n, which
+// is shrunk by rnd.
+// The dynamic window shrinks both sides by the same ratio, which is the same as
+// word2vec for the symmetric window.
+func (w window) bounds(pos, n int, rnd *modelutil.Random) (int, int) {
+	left, right := w.left, w.right
+	if !w.fixed {
+		m := left
+		if right > m {
+			m = right
+		}
+		del := rnd.Next(m)
+		left, right = left-del*left/m, right-del*right/m
+	}
+	s, e := pos-left, pos+right+1
+	if s < 0 {
+		s = 0
+	}
+	if e > n {
+		e = n
+	}
+	return s, e
+}
+
+// position returns the index of the relative position of the context c to pos in [0, size()).
+func (w window) position(pos, c int) int {
+	p := c - pos + w.left
+	if c > pos {
+		p--
+	}
+	return p
+}
+
+type skipGram[T num.Float] struct {
+	ch     chan []T
+	window window
+	// structured predicts the center word by the output parameters for each relative position.
+	structured bool
+}
+
+func newSkipGram[T num.Float](opts Options) mod[T] {
+	ch := make(chan []T, opts.Goroutines)
 	for i := 0; i < opts.Goroutines; i++ {
-		ch <- make([]float64, opts.Dim)
+		ch <- make([]T, opts.Dim)
 	}
-	return &skipGram{
-		ch:     ch,
-		window: opts.Window,
+	return &skipGram[T]{
+		ch:         ch,
+		window:     newWindow(opts),
+		structured: opts.ModelType == StructuredSkipGram,
 	}
 }
 
-func (mod *skipGram) trainOne(
+func (mod *skipGram[T]) trainOne(
 	doc []int,
 	pos int,
 	lr float64,
-	param *matrix.Matrix,
-	optimizer optimizer,
-) {
+	param *matrix.MatrixOf[T],
+	optimizer optimizer[T],
+	rnd *modelutil.Random,
+) (float64, int) {
 	tmp := <-mod.ch
 	defer func() {
 		mod.ch <- tmp
 	}()
-	del := modelutil.NextRandom(mod.window)
-	for a := del; a < mod.window*2+1-del; a++ {
-		if a == mod.window {
-			continue
-		}
-		c := pos - mod.window + a
-		if c < 0 || c >= len(doc) {
+	var (
+		loss float64
+		n    int
+	)
+	s, e := mod.window.bounds(pos, len(doc), rnd)
+	for c := s; c < e; c++ {
+		if c == pos {
 			continue
 		}
-		for i := 0; i < len(tmp); i++ {
-			tmp[i] = 0
-		}
-		ctxID := doc[c]
-		ctx := param.Slice(ctxID)
-		optimizer.optim(doc[pos], lr, ctx, tmp)
-		for i := 0; i < len(ctx); i++ {
-			ctx[i] += tmp[i]
+		vecmath.Zero(tmp)
+		ctx := param.Slice(doc[c])
+		var out int
+		if mod.structured {
+			out = mod.window.position(pos, c)
 		}
+		loss += optimizer.optim(doc[pos], out, lr, ctx, tmp, rnd)
+		n++
+		vecmath.Axpy(1, tmp, ctx)
 	}
+	return loss, n
 }
 
-type cbowToken struct {
-	agg []float64
-	tmp []float64
+type cbowToken[T num.Float] struct {
+	agg []T
+	tmp []T
 }
 
-type cbow struct {
-	ch     chan cbowToken
-	window int
+type cbow[T num.Float] struct {
+	ch     chan cbowToken[T]
+	window window
+	// mean averages the context vectors instead of summing them.
+	mean bool
 }
 
-func newCbow(opts Options) mod {
-	ch := make(chan cbowToken, opts.Goroutines)
+func newCbow[T num.Float](opts Options) mod[T] {
+	ch := make(chan cbowToken[T], opts.Goroutines)
 	for i := 0; i < opts.Goroutines; i++ {
-		ch <- cbowToken{
-			agg: make([]float64, opts.Dim),
-			tmp: make([]float64, opts.Dim),
+		ch <- cbowToken[T]{
+			agg: make([]T, opts.Dim),
+			tmp: make([]T, opts.Dim),
 		}
 	}
-	return &cbow{
+	return &cbow[T]{
 		ch:     ch,
-		window: opts.Window,
+		window: newWindow(opts),
+		mean:   opts.CbowMean,
 	}
 }
 
-func (mod *cbow) trainOne(
+func (mod *cbow[T]) trainOne(
 	doc []int,
 	pos int,
 	lr float64,
-	param *matrix.Matrix,
-	optimizer optimizer,
-) {
+	param *matrix.MatrixOf[T],
+	optimizer optimizer[T],
+	rnd *modelutil.Random,
+) (float64, int) {
 	token := <-mod.ch
 	agg, tmp := token.agg, token.tmp
 	defer func() {
-		token := cbowToken{agg, tmp}
+		token := cbowToken[T]{agg, tmp}
 		mod.ch <- token
 	}()
-	for i := 0; i < len(agg); i++ {
-		agg[i], tmp[i] = 0, 0
-	}
-	mod.dowith(doc, pos, param, agg, tmp, mod.aggregate)
-	optimizer.optim(doc[pos], lr, agg, tmp)
-	mod.dowith(doc, pos, param, agg, tmp, mod.update)
-}
+	vecmath.Zero(agg)
+	vecmath.Zero(tmp)
 
-func (mod *cbow) dowith(
-	doc []int,
-	pos int,
-	param *matrix.Matrix,
-	agg, tmp []float64,
-	fn func(ctx, agg, tmp []float64),
-) {
-	del := modelutil.NextRandom(mod.window)
-	for a := del; a < mod.window*2+1-del; a++ {
-		if a == mod.window {
+	// the same window is used for aggregating and updating the context vectors.
+	s, e := mod.window.bounds(pos, len(doc), rnd)
+	var cnt int
+	for c := s; c < e; c++ {
+		if c == pos {
 			continue
 		}
-		c := pos - mod.window + a
-		if c < 0 || c >= len(doc) {
+		vecmath.Axpy(1, param.Slice(doc[c]), agg)
+		cnt++
+	}
+	if cnt == 0 {
+		return 0, 0
+	}
+	if mod.mean {
+		vecmath.Scale(1/T(cnt), agg)
+	}
+	loss := optimizer.optim(doc[pos], 0, lr, agg, tmp, rnd)
+	// the gradient of the mean is shared by 1/cnt among the contexts.
+	if mod.mean {
+		vecmath.Scale(1/T(cnt), tmp)
+	}
+	for c := s; c < e; c++ {
+		if c == pos {
 			continue
 		}
-		ctxID := doc[c]
-		ctx := param.Slice(ctxID)
-		fn(ctx, agg, tmp)
+		vecmath.Axpy(1, tmp, param.Slice(doc[c]))
 	}
+	return loss, 1
+}
+
+// cwindow is CBOW which concatenates the context vectors in the order of position.
+type cwindow[T num.Float] struct {
+	ch     chan cbowToken[T]
+	window window
+	dim    int
 }
 
-func (c *cbow) aggregate(ctx, agg, _ []float64) {
-	for i := 0; i < len(ctx); i++ {
-		agg[i] += ctx[i]
+func newCWindow[T num.Float](opts Options) mod[T] {
+	w := newWindow(opts)
+	ch := make(chan cbowToken[T], opts.Goroutines)
+	for i := 0; i < opts.Goroutines; i++ {
+		ch <- cbowToken[T]{
+			agg: make([]T, w.size()*opts.Dim),
+			tmp: make([]T, w.size()*opts.Dim),
+		}
+	}
+	return &cwindow[T]{
+		ch:     ch,
+		window: w,
+		dim:    opts.Dim,
 	}
 }
 
-func (c *cbow) update(ctx, _, tmp []float64) {
-	for i := 0; i < len(ctx); i++ {
-		ctx[i] += tmp[i]
+func (mod *cwindow[T]) trainOne(
+	doc []int,
+	pos int,
+	lr float64,
+	param *matrix.MatrixOf[T],
+	optimizer optimizer[T],
+	rnd *modelutil.Random,
+) (float64, int) {
+	token := <-mod.ch
+	defer func() {
+		mod.ch <- token
+	}()
+	agg, tmp := token.agg, token.tmp
+	vecmath.Zero(agg)
+	vecmath.Zero(tmp)
+
+	s, e := mod.window.bounds(pos, len(doc), rnd)
+	segment := func(vec []T, c int) []T {
+		p := mod.window.position(pos, c)
+		return vec[p*mod.dim : (p+1)*mod.dim]
+	}
+	for c := s; c < e; c++ {
+		if c == pos {
+			continue
+		}
+		copy(segment(agg, c), param.Slice(doc[c]))
+	}
+	loss := optimizer.optim(doc[pos], 0, lr, agg, tmp, rnd)
+	for c := s; c < e; c++ {
+		if c == pos {
+			continue
+		}
+		vecmath.Axpy(1, segment(tmp, c), param.Slice(doc[c]))
 	}
+	return loss, 1
 }
@@ -0,0 +1,273 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+const (
+	gradVocab = 9
+	gradDim   = 4
+	gradLR    = 0.1
+	// gradDelta is the tolerance for the updates, due to the approximation by sigmoid table.
+	gradDelta = 1e-3
+)
+
+// numGrad returns the gradient of f at x by the central difference.
+func numGrad(f func([]float64) float64, x []float64) []float64 {
+	const eps = 1e-6
+	g := make([]float64, len(x))
+	for i := range x {
+		orig := x[i]
+		x[i] = orig + eps
+		fp := f(x)
+		x[i] = orig - eps
+		fm := f(x)
+		x[i] = orig
+		g[i] = (fp - fm) / (2 * eps)
+	}
+	return g
+}
+
+func randomMatrix(rnd *rand.Rand, row, col int) *matrix.MatrixOf[float64] {
+	return matrix.New(row, col, func(_ int, vec []float64) {
+		for i := range vec {
+			vec[i] = rnd.Float64() - 0.5
+		}
+	})
+}
+
+func copyRows(mat *matrix.MatrixOf[float64]) [][]float64 {
+	res := make([][]float64, mat.Row())
+	for i := range res {
+		res[i] = append([]float64(nil), mat.Slice(i)...)
+	}
+	return res
+}
+
+// expectBounds checks that [s, e) is the window of pos for some shrink of w.
+func expectBounds(t *testing.T, w window, pos, s, e int) {
+	if w.fixed {
+		assert.Equal(t, [2]int{pos - w.left, pos + w.right + 1}, [2]int{s, e})
+		return
+	}
+	m := w.left
+	if w.right > m {
+		m = w.right
+	}
+	for del := 0; del < m; del++ {
+		if s == pos-(w.left-del*w.left/m) && e == pos+(w.right-del*w.right/m)+1 {
+			return
+		}
+	}
+	t.Errorf("unexpected window [%d, %d) for %d in left=%d, right=%d", s, e, pos, w.left, w.right)
+}
+
+// changedBounds returns the interval of the rows changed by training except pos.
+func changedBounds(before [][]float64, after *matrix.MatrixOf[float64], pos int) (int, int) {
+	s, e := pos, pos+1
+	for i := range before {
+		if i == pos {
+			continue
+		}
+		if fmt.Sprint(before[i]) != fmt.Sprint(after.Slice(i)) {
+			if i < s {
+				s = i
+			}
+			if i >= e {
+				e = i + 1
+			}
+		}
+	}
+	return s, e
+}
+
+func TestGradient(t *testing.T) {
+	// the words in the doc are all different, so that the updated rows tell the contexts.
+	doc := make([]int, gradVocab)
+	for i := range doc {
+		doc[i] = i
+	}
+	pos := gradVocab / 2
+
+	for _, modelType := range []ModelType{SkipGram, Cbow} {
+		for _, mean := range []bool{false, true} {
+			if modelType == SkipGram && mean {
+				continue
+			}
+			for _, fixed := range []bool{true, false} {
+				for _, lr := range [][2]int{{2, 2}, {3, 1}, {0, 2}, {2, 0}} {
+					opts := DefaultOptions()
+					opts.ModelType = modelType
+					opts.CbowMean = mean
+					opts.FixedWindow = fixed
+					opts.Goroutines = 1
+					opts.Dim = gradDim
+					opts.LeftWindow, opts.RightWindow = lr[0], lr[1]
+					t.Run(fmt.Sprintf("%s/mean=%t/fixed=%t/left=%d,right=%d", modelType, mean, fixed, lr[0], lr[1]), func(t *testing.T) {
+						for trial := 0; trial < 20; trial++ {
+							testGradient(t, opts, doc, pos, int64(trial))
+						}
+					})
+				}
+			}
+		}
+	}
+}
+
+func testGradient(t *testing.T, opts Options, doc []int, pos int, seed int64) {
+	rnd := rand.New(rand.NewSource(seed))
+	param := randomMatrix(rnd, gradVocab, gradDim)
+	// no negative samples, so that the loss is deterministic: -log(sigmoid(h・out)).
+	opt := &negativeSampling[float64]{
+		ctx:        randomMatrix(rnd, gradVocab, gradDim),
+		vocab:      gradVocab,
+		sigtable:   newSigmoidTable(),
+		sampleSize: 0,
+	}
+	in, out := copyRows(param), copyRows(opt.ctx)
+
+	var m mod[float64]
+	if opts.ModelType == Cbow {
+		m = newCbow[float64](opts)
+	} else {
+		m = newSkipGram[float64](opts)
+	}
+	m.trainOne(doc, pos, gradLR, param, opt, modelutil.NewRandom(seed))
+
+	w := newWindow(opts)
+	s, e := changedBounds(in, param, pos)
+	expectBounds(t, w, pos, s, e)
+
+	target := out[doc[pos]]
+	expectIn := copyRows(param)
+	switch opts.ModelType {
+	case Cbow:
+		var cnt float64
+		for c := s; c < e; c++ {
+			if c != pos {
+				cnt++
+			}
+		}
+		hidden := func(replace int, x []float64) []float64 {
+			h := make([]float64, gradDim)
+			for c := s; c < e; c++ {
+				if c == pos {
+					continue
+				}
+				v := in[doc[c]]
+				if c == replace {
+					v = x
+				}
+				vecmath.Axpy(1, v, h)
+			}
+			if opts.CbowMean {
+				vecmath.Scale(1/cnt, h)
+			}
+			return h
+		}
+		for c := s; c < e; c++ {
+			if c == pos {
+				continue
+			}
+			grad := numGrad(func(x []float64) float64 {
+				return modelutil.LogLoss(1, vecmath.Dot(hidden(c, x), target))
+			}, append([]float64(nil), in[doc[c]]...))
+			expectIn[doc[c]] = append([]float64(nil), in[doc[c]]...)
+			vecmath.Axpy(-gradLR, grad, expectIn[doc[c]])
+		}
+		h := hidden(-1, nil)
+		grad := numGrad(func(x []float64) float64 {
+			return modelutil.LogLoss(1, vecmath.Dot(h, x))
+		}, append([]float64(nil), target...))
+		vecmath.Axpy(-gradLR, grad, target)
+	case SkipGram:
+		// the output vector is updated by each context in order.
+		for c := s; c < e; c++ {
+			if c == pos {
+				continue
+			}
+			v := in[doc[c]]
+			grad := numGrad(func(x []float64) float64 {
+				return modelutil.LogLoss(1, vecmath.Dot(x, target))
+			}, append([]float64(nil), v...))
+			expectIn[doc[c]] = append([]float64(nil), v...)
+			vecmath.Axpy(-gradLR, grad, expectIn[doc[c]])
+			grad = numGrad(func(x []float64) float64 {
+				return modelutil.LogLoss(1, vecmath.Dot(v, x))
+			}, append([]float64(nil), target...))
+			vecmath.Axpy(-gradLR, grad, target)
+		}
+	}
+
+	for i := range expectIn {
+		assert.InDeltaSlice(t, expectIn[i], param.Slice(i), gradDelta)
+	}
+	assert.InDeltaSlice(t, target, opt.ctx.Slice(doc[pos]), gradDelta)
+}
+
+func TestTrainWindows(t *testing.T) {
+	doc := make([]int, 50)
+	for i := range doc {
+		doc[i] = i % gradVocab
+	}
+	for _, modelType := range []ModelType{Cbow, SkipGram, StructuredSkipGram, CWindow} {
+		for _, lr := range [][2]int{{3, 1}, {0, 2}, {2, 0}} {
+			t.Run(fmt.Sprintf("%s/left=%d,right=%d", modelType, lr[0], lr[1]), func(t *testing.T) {
+				opts := DefaultOptions()
+				opts.ModelType = modelType
+				opts.Goroutines = 1
+				opts.Dim = gradDim
+				opts.LeftWindow, opts.RightWindow = lr[0], lr[1]
+				outputs, dim := outputShape(opts)
+				if modelType == StructuredSkipGram {
+					assert.Equal(t, lr[0]+lr[1], outputs)
+				}
+				if modelType == CWindow {
+					assert.Equal(t, (lr[0]+lr[1])*gradDim, dim)
+				}
+				var m mod[float64]
+				switch modelType {
+				case Cbow:
+					m = newCbow[float64](opts)
+				case CWindow:
+					m = newCWindow[float64](opts)
+				default:
+					m = newSkipGram[float64](opts)
+				}
+				param := randomMatrix(rand.New(rand.NewSource(1)), gradVocab, gradDim)
+				opt := &negativeSampling[float64]{
+					ctx:        randomMatrix(rand.New(rand.NewSource(2)), gradVocab*outputs, dim),
+					vocab:      gradVocab,
+					sigtable:   newSigmoidTable(),
+					sampleSize: 2,
+				}
+				rnd := modelutil.NewRandom(1)
+				for pos := range doc {
+					m.trainOne(doc, pos, gradLR, param, opt, rnd)
+				}
+			})
+		}
+	}
+}
@@ -18,63 +18,68 @@ import (
 	"math/rand"
 
 	"github.com/ynqa/wego/pkg/corpus/dictionary"
-	"github.com/ynqa/wego/pkg/corpus/dictionary/node"
 	"github.com/ynqa/wego/pkg/model/modelutil"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
 )
 
-type optimizer interface {
-	optim(id int, lr float64, ctx, tmp []float64)
+// optimizer predicts the word id from the hidden vector ctx with the output parameters
+// of out, and accumulates the gradient for ctx into tmp. The models which have only one
+// set of output parameters use out=0. rnd is the random generator of the worker.
+type optimizer[T num.Float] interface {
+	optim(id, out int, lr float64, ctx, tmp []T, rnd *modelutil.Random) float64
 }
 
-type negativeSampling struct {
-	ctx        *matrix.Matrix
+type negativeSampling[T num.Float] struct {
+	// ctx stores the output vectors of the word id for out in the row out*vocab+id.
+	ctx        *matrix.MatrixOf[T]
+	vocab      int
 	sigtable   *sigmoidTable
 	sampleSize int
 }
 
-func newNegativeSampling(dic *dictionary.Dictionary, opts Options) optimizer {
-	return &negativeSampling{
+func newNegativeSampling[T num.Float](dic *dictionary.Dictionary, opts Options, outputs, dim int, rnd *rand.Rand) optimizer[T] {
+	return &negativeSampling[T]{
 		ctx: matrix.New(
-			dic.Len(),
-			opts.Dim,
-			func(_ int, vec []float64) {
-				for i := 0; i < opts.Dim; i++ {
-					vec[i] = (rand.Float64() - 0.5) / float64(opts.Dim)
+			dic.Len()*outputs,
+			dim,
+			func(_ int, vec []T) {
+				for i := 0; i < dim; i++ {
+					vec[i] = T((rnd.Float64() - 0.5) / float64(dim))
 				}
 			},
 		),
+		vocab:      dic.Len(),
 		sigtable:   newSigmoidTable(),
 		sampleSize: opts.NegativeSampleSize,
 	}
 }
 
-func (opt *negativeSampling) optim(
-	id int,
+func (opt *negativeSampling[T]) optim(
+	id, out int,
 	lr float64,
-	ctx, tmp []float64,
-) {
+	ctx, tmp []T,
+	rnd *modelutil.Random,
+) float64 {
 	var (
 		label  int
 		picked int
+		loss   float64
 	)
-	dim := len(ctx)
 	for n := -1; n < opt.sampleSize; n++ {
 		if n == -1 {
 			label = 1
 			picked = id
 		} else {
 			label = 0
-			picked = modelutil.NextRandom(opt.ctx.Row())
+			picked = rnd.Next(opt.vocab)
 			if id == picked {
 				continue
 			}
 		}
-		rnd := opt.ctx.Slice(picked)
-		var inner float64
-		for i := 0; i < dim; i++ {
-			inner += rnd[i] * ctx[i]
-		}
+		vec := opt.ctx.Slice(out*opt.vocab + picked)
+		inner := float64(vecmath.Dot(vec, ctx))
 		var g float64
 		if inner <= -opt.sigtable.maxExp {
 			g = (float64(label - 0)) * lr
@@ -83,47 +88,58 @@ func (opt *negativeSampling) optim(
 		} else {
 			g = (float64(label) - opt.sigtable.sigmoid(inner)) * lr
 		}
-		for i := 0; i < dim; i++ {
-			tmp[i] += g * rnd[i]
-			rnd[i] += g * ctx[i]
-		}
+		loss += modelutil.LogLoss(label, inner)
+		vecmath.Axpy(T(g), vec, tmp)
+		vecmath.Axpy(T(g), ctx, vec)
 	}
+	return loss
 }
 
-type hierarchicalSoftmax struct {
+type hierarchicalSoftmax[T num.Float] struct {
 	sigtable *sigmoidTable
-	nodeset  []*node.Node
+	huffman  *dictionary.Huffman
+	// nodes stores the inner node vectors of the point for out in the row out*inner+point.
+	nodes    *matrix.MatrixOf[T]
+	inner    int
 	maxDepth int
 }
 
-func newHierarchicalSoftmax(dic *dictionary.Dictionary, opts Options) optimizer {
-	return &hierarchicalSoftmax{
+func newHierarchicalSoftmax[T num.Float](dic *dictionary.Dictionary, opts Options, outputs, dim int) optimizer[T] {
+	inner := dic.Len() - 1
+	if inner < 0 {
+		inner = 0
+	}
+	return &hierarchicalSoftmax[T]{
 		sigtable: newSigmoidTable(),
-		nodeset:  dic.HuffnamTree(opts.Dim),
+		huffman:  dic.Huffman(),
+		nodes:    matrix.New(inner*outputs, dim, func(int, []T) {}),
+		inner:    inner,
 		maxDepth: opts.MaxDepth,
 	}
 }
 
-func (opt *hierarchicalSoftmax) optim(
-	id int,
+func (opt *hierarchicalSoftmax[T]) optim(
+	id, out int,
 	lr float64,
-	ctx, tmp []float64,
-) {
-	path := opt.nodeset[id].GetPath(opt.maxDepth)
-	for i := 0; i < len(path)-1; i++ {
-		p := path[i]
-		childCode := path[i+1].Code
-		var inner float64
-		for j := 0; j < len(p.Vector); j++ {
-			inner += ctx[j] * p.Vector[j]
-		}
+	ctx, tmp []T,
+	rnd *modelutil.Random,
+) float64 {
+	var loss float64
+	codes, points := opt.huffman.Path(id)
+	if opt.maxDepth > 0 && len(points) > opt.maxDepth {
+		codes, points = codes[:opt.maxDepth], points[:opt.maxDepth]
+	}
+	for i, point := range points {
+		vec := opt.nodes.Slice(out*opt.inner + point)
+		inner := float64(vecmath.Dot(ctx, vec))
 		if inner <= -opt.sigtable.maxExp || inner >= opt.sigtable.maxExp {
-			return
-		}
-		g := (1.0 - float64(childCode) - opt.sigtable.sigmoid(inner)) * lr
-		for j := 0; j < len(p.Vector); j++ {
-			tmp[j] += g * p.Vector[j]
-			p.Vector[j] += g * ctx[j]
+			return loss
 		}
+		code := int(codes[i])
+		g := (1.0 - float64(code) - opt.sigtable.sigmoid(inner)) * lr
+		loss += modelutil.LogLoss(1-code, inner)
+		vecmath.Axpy(T(g), vec, tmp)
+		vecmath.Axpy(T(g), ctx, vec)
 	}
+	return loss
 }
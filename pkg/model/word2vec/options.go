@@ -17,8 +17,13 @@ package word2vec
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/validate"
 )
 
 type ModelType = string
@@ -26,6 +31,9 @@ type ModelType = string
 const (
 	Cbow     ModelType = "cbow"
 	SkipGram ModelType = "skipgram"
+	// StructuredSkipGram and CWindow are the position-aware variants (Ling et al. 2015).
+	StructuredSkipGram ModelType = "sskipgram"
+	CWindow            ModelType = "cwindow"
 )
 
 type OptimizerType = string
@@ -37,11 +45,15 @@ const (
 
 var (
 	defaultBatchSize          = 10000
+	defaultCbowMean           = false
 	defaultDim                = 10
 	defaultDocInMemory        = false
+	defaultFixedWindow        = false
 	defaultGoroutines         = runtime.NumCPU()
 	defaultInitlr             = 0.025
 	defaultIter               = 15
+	defaultLRSchedule         = schedule.Linear
+	defaultLeftWindow         = -1
 	defaultLogBatch           = 100000
 	defaultMaxCount           = -1
 	defaultMaxDepth           = 100
@@ -50,20 +62,31 @@ var (
 	defaultModelType          = Cbow
 	defaultNegativeSampleSize = 5
 	defaultOptimizerType      = NegativeSampling
+	defaultPrecision          = matrix.Float64
+	defaultRightWindow        = -1
+	defaultSeed               = int64(1)
+	defaultShuffle            = false
+	defaultStepDecay          = 0.5
 	defaultSubsampleThreshold = 1.0e-3
+	defaultTimeBudget         = time.Duration(0)
 	defaultToLower            = false
 	defaultUpdateLRBatch      = 100000
 	defaultVerbose            = false
+	defaultWarmupRatio        = 0.1
 	defaultWindow             = 5
 )
 
 type Options struct {
 	BatchSize          int
+	CbowMean           bool
 	Dim                int
 	DocInMemory        bool
+	FixedWindow        bool
 	Goroutines         int
 	Initlr             float64
 	Iter               int
+	LRSchedule         schedule.Type
+	LeftWindow         int
 	LogBatch           int
 	MaxCount           int
 	MaxDepth           int
@@ -72,21 +95,32 @@ type Options struct {
 	ModelType          ModelType
 	NegativeSampleSize int
 	OptimizerType      OptimizerType
+	Precision          matrix.Precision
+	RightWindow        int
+	Seed               int64
+	Shuffle            bool
+	StepDecay          float64
 	SubsampleThreshold float64
+	TimeBudget         time.Duration
 	ToLower            bool
 	UpdateLRBatch      int
 	Verbose            bool
+	WarmupRatio        float64
 	Window             int
 }
 
 func DefaultOptions() Options {
 	return Options{
 		BatchSize:          defaultBatchSize,
+		CbowMean:           defaultCbowMean,
 		Dim:                defaultDim,
 		DocInMemory:        defaultDocInMemory,
+		FixedWindow:        defaultFixedWindow,
 		Goroutines:         defaultGoroutines,
 		Initlr:             defaultInitlr,
 		Iter:               defaultIter,
+		LRSchedule:         defaultLRSchedule,
+		LeftWindow:         defaultLeftWindow,
 		LogBatch:           defaultLogBatch,
 		MaxCount:           defaultMaxCount,
 		MaxDepth:           defaultMaxDepth,
@@ -95,34 +129,95 @@ func DefaultOptions() Options {
 		ModelType:          defaultModelType,
 		NegativeSampleSize: defaultNegativeSampleSize,
 		OptimizerType:      defaultOptimizerType,
+		Precision:          defaultPrecision,
+		RightWindow:        defaultRightWindow,
+		Seed:               defaultSeed,
+		Shuffle:            defaultShuffle,
+		StepDecay:          defaultStepDecay,
 		SubsampleThreshold: defaultSubsampleThreshold,
+		TimeBudget:         defaultTimeBudget,
 		ToLower:            defaultToLower,
 		UpdateLRBatch:      defaultUpdateLRBatch,
 		Verbose:            defaultVerbose,
+		WarmupRatio:        defaultWarmupRatio,
 		Window:             defaultWindow,
 	}
 }
 
+// Validate reports all invalid values of Options at once.
+func (opts Options) Validate() error {
+	v := validate.New()
+	v.Positive("BatchSize", opts.BatchSize)
+	v.Positive("Dim", opts.Dim)
+	v.Positive("Goroutines", opts.Goroutines)
+	v.PositiveFloat("Initlr", opts.Initlr)
+	v.Positive("Iter", opts.Iter)
+	v.OneOf("LRSchedule", opts.LRSchedule, schedule.Linear, schedule.Constant, schedule.Cosine, schedule.Step, schedule.Warmup)
+	v.Positive("LogBatch", opts.LogBatch)
+	v.NonNegativeFloat("MinLR", opts.MinLR)
+	v.Check(opts.MinLR <= opts.Initlr, "MinLR", opts.MinLR, fmt.Sprintf("must be <= Initlr=%v", opts.Initlr))
+	v.NonNegative("MaxDepth", opts.MaxDepth)
+	v.OneOf("ModelType", opts.ModelType, Cbow, SkipGram, StructuredSkipGram, CWindow)
+	v.NonNegative("NegativeSampleSize", opts.NegativeSampleSize)
+	v.OneOf("OptimizerType", opts.OptimizerType, NegativeSampling, HierarchicalSoftmax)
+	v.OneOf("Precision", opts.Precision, matrix.Float64, matrix.Float32)
+	v.Range("StepDecay", opts.StepDecay, 0, 1)
+	v.NonNegativeFloat("SubsampleThreshold", opts.SubsampleThreshold)
+	v.Check(opts.TimeBudget >= 0, "TimeBudget", opts.TimeBudget, "must be >= 0")
+	v.Positive("UpdateLRBatch", opts.UpdateLRBatch)
+	v.Check(0 <= opts.WarmupRatio && opts.WarmupRatio < 1, "WarmupRatio", opts.WarmupRatio, "must be in [0, 1)")
+	// windows falls back to Window only for the negative LeftWindow or RightWindow.
+	if opts.LeftWindow < 0 || opts.RightWindow < 0 {
+		v.Positive("Window", opts.Window)
+	}
+	left, right := opts.windows()
+	v.Check(left+right > 0, "LeftWindow", opts.LeftWindow, fmt.Sprintf("must make the window non-empty with RightWindow=%d", opts.RightWindow))
+	return v.Err()
+}
+
+// windows returns the context window sizes on the left and the right.
+func (opts Options) windows() (int, int) {
+	left, right := opts.LeftWindow, opts.RightWindow
+	if left < 0 {
+		left = opts.Window
+	}
+	if right < 0 {
+		right = opts.Window
+	}
+	return left, right
+}
+
 func LoadForCmd(cmd *cobra.Command, opts *Options) {
-	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train")
-	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector")
-	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine")
+	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train (> 0)")
+	cmd.Flags().BoolVar(&opts.CbowMean, "cbow-mean", defaultCbowMean, "whether to average the context vectors instead of summing them (for cbow only)")
+	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector (> 0)")
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine (> 0)")
 	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", defaultDocInMemory, "whether to store the doc in memory")
-	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate")
-	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration")
-	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words")
+	cmd.Flags().BoolVar(&opts.FixedWindow, "fixed-window", defaultFixedWindow, "whether to use the full window instead of shrinking it randomly for each word")
+	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate (> 0)")
+	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration (> 0)")
+	cmd.Flags().StringVar(&opts.LRSchedule, "lr-schedule", defaultLRSchedule, fmt.Sprintf("learning rate schedule over total training progress. One of: %s|%s|%s|%s|%s", schedule.Linear, schedule.Constant, schedule.Cosine, schedule.Step, schedule.Warmup))
+	cmd.Flags().IntVar(&opts.LeftWindow, "left-window", defaultLeftWindow, "context window size on the left (same as window if negative; left-window and right-window must not both be zero)")
+	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting words (> 0)")
 	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words")
-	cmd.Flags().IntVar(&opts.MaxDepth, "max-depth", defaultMaxDepth, "times to track huffman tree, max-depth=0 means to track full path from root to word (for hierarchical softmax only)")
+	cmd.Flags().IntVar(&opts.MaxDepth, "max-depth", defaultMaxDepth, "number of inner nodes to track on huffman tree, max-depth=0 means to track full path from root to word (for hierarchical softmax only; >= 0)")
 	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words")
-	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", defaultMinLR, "lower limit of learning rate")
-	cmd.Flags().StringVar(&opts.ModelType, "model", defaultModelType, fmt.Sprintf("which model does it use? one of: %s|%s", Cbow, SkipGram))
-	cmd.Flags().IntVar(&opts.NegativeSampleSize, "sample", defaultNegativeSampleSize, "negative sample size(for negative sampling only)")
+	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", defaultMinLR, "lower limit of learning rate (in [0, initlr])")
+	cmd.Flags().StringVar(&opts.ModelType, "model", defaultModelType, fmt.Sprintf("which model does it use? one of: %s|%s|%s|%s", Cbow, SkipGram, StructuredSkipGram, CWindow))
+	cmd.Flags().IntVar(&opts.NegativeSampleSize, "sample", defaultNegativeSampleSize, "negative sample size(for negative sampling only; >= 0)")
 	cmd.Flags().StringVar(&opts.OptimizerType, "optimizer", defaultOptimizerType, fmt.Sprintf("which optimizer does it use? one of: %s|%s", HierarchicalSoftmax, NegativeSampling))
-	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling")
+	cmd.Flags().StringVar(&opts.Precision, "precision", defaultPrecision, fmt.Sprintf("floating point type to store parameters. One of: %s|%s", matrix.Float64, matrix.Float32))
+	cmd.Flags().IntVar(&opts.RightWindow, "right-window", defaultRightWindow, "context window size on the right (same as window if negative; left-window and right-window must not both be zero)")
+	cmd.Flags().Int64Var(&opts.Seed, "seed", defaultSeed, "random seed for initialization, sampling and shuffling")
+	cmd.Flags().BoolVar(&opts.Shuffle, "shuffle", defaultShuffle, "whether to shuffle the chunks of batch size words every iteration (for in-memory only)")
+	cmd.Flags().Float64Var(&opts.StepDecay, "step-decay", defaultStepDecay, "factor to multiply learning rate at each epoch (for step schedule only; in [0, 1])")
+	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling (>= 0)")
+	cmd.Flags().DurationVar(&opts.TimeBudget, "time-budget", defaultTimeBudget, "wall-clock budget for training, e.g. 30m (no limit if zero; >= 0)")
 	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on corpus convert to lowercase or not")
-	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", defaultUpdateLRBatch, "batch size to update learning rate")
+	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", defaultUpdateLRBatch, "batch size to update learning rate (> 0)")
 	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
-	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size")
+	cmd.Flags().Float64Var(&opts.WarmupRatio, "warmup-ratio", defaultWarmupRatio, "ratio of training to increase learning rate linearly (for warmup schedule only; in [0, 1))")
+	cmd.Flags().IntVarP(&opts.Window, "window", "w", defaultWindow, "context window size (> 0 unless both left-window and right-window are set)")
 }
 
 type ModelOption func(*Options)
@@ -133,12 +228,24 @@ func BatchSize(v int) ModelOption {
 	})
 }
 
+func CbowMean() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.CbowMean = true
+	})
+}
+
 func DocInMemory() ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.DocInMemory = true
 	})
 }
 
+func FixedWindow() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.FixedWindow = true
+	})
+}
+
 func Goroutines(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Goroutines = v
@@ -163,6 +270,18 @@ func Iter(v int) ModelOption {
 	})
 }
 
+func LRSchedule(v schedule.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRSchedule = v
+	})
+}
+
+func LeftWindow(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LeftWindow = v
+	})
+}
+
 func LogBatch(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.LogBatch = v
@@ -211,12 +330,48 @@ func Optimizer(typ OptimizerType) ModelOption {
 	})
 }
 
+func Precision(typ matrix.Precision) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Precision = typ
+	})
+}
+
+func RightWindow(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.RightWindow = v
+	})
+}
+
+func Seed(v int64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Seed = v
+	})
+}
+
+func Shuffle(v bool) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Shuffle = v
+	})
+}
+
+func StepDecay(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.StepDecay = v
+	})
+}
+
 func SubsampleThreshold(v float64) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.SubsampleThreshold = v
 	})
 }
 
+func TimeBudget(v time.Duration) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.TimeBudget = v
+	})
+}
+
 func ToLower() ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.ToLower = true
@@ -235,6 +390,12 @@ func Verbose() ModelOption {
 	})
 }
 
+func WarmupRatio(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.WarmupRatio = v
+	})
+}
+
 func Window(v int) ModelOption {
 	return ModelOption(func(opts *Options) {
 		opts.Window = v
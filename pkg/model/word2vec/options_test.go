@@ -0,0 +1,65 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/validate"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, DefaultOptions().Validate())
+
+	_, err := New(
+		Dim(0),
+		Goroutines(0),
+		MinLR(1),
+		Model("invalid"),
+		UpdateLRBatch(0),
+	)
+	var errs validate.Errors
+	assert.True(t, errors.As(err, &errs))
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	assert.Equal(t, []string{"Dim", "Goroutines", "MinLR", "ModelType", "UpdateLRBatch"}, fields)
+}
+
+func TestValidateWindows(t *testing.T) {
+	opts := DefaultOptions()
+	opts.LeftWindow = 0
+	assert.NoError(t, opts.Validate())
+	left, right := opts.windows()
+	assert.Equal(t, [2]int{0, opts.Window}, [2]int{left, right})
+
+	opts.RightWindow = 0
+	assert.Error(t, opts.Validate())
+}
+
+func TestValidateWindowUnused(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Window = 0
+	opts.LeftWindow = 2
+	opts.RightWindow = 2
+	assert.NoError(t, opts.Validate())
+
+	opts.RightWindow = -1
+	assert.Error(t, opts.Validate())
+}
@@ -19,33 +19,37 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
-	"sync"
-
-	"golang.org/x/sync/semaphore"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/ynqa/wego/pkg/corpus"
-	"github.com/ynqa/wego/pkg/corpus/fs"
-	"github.com/ynqa/wego/pkg/corpus/memory"
 	"github.com/ynqa/wego/pkg/model"
 	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/loss"
 	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/progress"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/shuffle"
 	"github.com/ynqa/wego/pkg/model/modelutil/subsample"
 	"github.com/ynqa/wego/pkg/model/modelutil/vector"
 	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/num"
 	"github.com/ynqa/wego/pkg/util/verbose"
 )
 
-type word2vec struct {
+type word2vec[T num.Float] struct {
 	opts Options
 
 	corpus corpus.Corpus
 
-	param      *matrix.Matrix
+	param      *matrix.MatrixOf[T]
 	subsampler *subsample.Subsampler
-	currentlr  float64
-	mod        mod
-	optimizer  optimizer
+	schedule   schedule.Schedule
+	progress   *progress.Progress
+	mod        mod[T]
+	optimizer  optimizer[T]
+	loss       *loss.Loss
+	epochTimes []time.Duration
 
 	verbose *verbose.Verbose
 }
@@ -60,196 +64,256 @@ func New(opts ...ModelOption) (model.Model, error) {
 }
 
 func NewForOptions(opts Options) (model.Model, error) {
-	// TODO: validate Options
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Precision == matrix.Float32 {
+		return newWord2vec[float32](opts), nil
+	}
+	return newWord2vec[float64](opts), nil
+}
+
+func newWord2vec[T num.Float](opts Options) *word2vec[T] {
 	v := verbose.New(opts.Verbose)
-	return &word2vec{
+	return &word2vec[T]{
 		opts: opts,
 
-		currentlr: opts.Initlr,
+		loss: loss.New(),
 
 		verbose: v,
-	}, nil
+	}
 }
 
-func (w *word2vec) Train(r io.ReadSeeker) error {
-	if w.opts.DocInMemory {
-		w.corpus = memory.New(r, w.opts.ToLower, w.opts.MaxCount, w.opts.MinCount)
-	} else {
-		w.corpus = fs.New(r, w.opts.ToLower, w.opts.MaxCount, w.opts.MinCount)
+func (w *word2vec[T]) Train(ctx context.Context, r io.ReadSeeker) error {
+	if w.opts.TimeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.opts.TimeBudget)
+		defer cancel()
 	}
 
-	if err := w.corpus.Load(nil, w.verbose, w.opts.LogBatch); err != nil {
+	c, err := modelutil.LoadCorpus(
+		ctx, r,
+		corpus.Key{
+			InMemory: w.opts.DocInMemory,
+			ToLower:  w.opts.ToLower,
+			MaxCount: w.opts.MaxCount,
+			MinCount: w.opts.MinCount,
+		},
+		nil,
+		w.verbose, w.opts.LogBatch,
+	)
+	if err != nil {
 		return err
 	}
+	w.corpus = c
 
 	dic, dim := w.corpus.Dictionary(), w.opts.Dim
 
+	rnd := rand.New(rand.NewSource(w.opts.Seed))
 	w.param = matrix.New(
 		dic.Len(),
 		dim,
-		func(_ int, vec []float64) {
+		func(_ int, vec []T) {
 			for i := 0; i < dim; i++ {
-				vec[i] = (rand.Float64() - 0.5) / float64(dim)
+				vec[i] = T((rnd.Float64() - 0.5) / float64(dim))
 			}
 		},
 	)
 
+	sched, err := schedule.New(w.opts.LRSchedule, schedule.Config{
+		Initlr:      w.opts.Initlr,
+		MinLR:       w.opts.MinLR,
+		Iter:        w.opts.Iter,
+		WarmupRatio: w.opts.WarmupRatio,
+		StepDecay:   w.opts.StepDecay,
+	})
+	if err != nil {
+		return err
+	}
+	w.schedule = sched
+	w.progress = progress.New(w.corpus.FilteredLen()*w.opts.Iter, w.opts.LogBatch)
+
 	w.subsampler = subsample.New(dic, w.opts.SubsampleThreshold)
 
 	switch w.opts.ModelType {
 	case SkipGram:
-		w.mod = newSkipGram(w.opts)
+		w.mod = newSkipGram[T](w.opts)
 	case Cbow:
-		w.mod = newCbow(w.opts)
+		w.mod = newCbow[T](w.opts)
+	case StructuredSkipGram:
+		w.mod = newSkipGram[T](w.opts)
+	case CWindow:
+		w.mod = newCWindow[T](w.opts)
 	default:
-		return errors.Errorf("invalid model: %s not in %s|%s", w.opts.ModelType, Cbow, SkipGram)
+		return errors.Errorf("invalid model: %s not in %s|%s|%s|%s", w.opts.ModelType, Cbow, SkipGram, StructuredSkipGram, CWindow)
 	}
 
+	outputs, outputDim := outputShape(w.opts)
+
 	switch w.opts.OptimizerType {
 	case NegativeSampling:
-		w.optimizer = newNegativeSampling(
+		w.optimizer = newNegativeSampling[T](
 			w.corpus.Dictionary(),
 			w.opts,
+			outputs, outputDim,
+			rnd,
 		)
 	case HierarchicalSoftmax:
-		w.optimizer = newHierarchicalSoftmax(
+		w.optimizer = newHierarchicalSoftmax[T](
 			w.corpus.Dictionary(),
 			w.opts,
+			outputs, outputDim,
 		)
 	default:
 		return errors.Errorf("invalid optimizer: %s not in %s|%s", w.opts.OptimizerType, NegativeSampling, HierarchicalSoftmax)
 	}
 
 	if w.opts.DocInMemory {
-		if err := w.train(); err != nil {
+		if err := w.train(ctx); err != nil {
 			return err
 		}
 	} else {
-		if err := w.batchTrain(); err != nil {
+		if err := w.batchTrain(ctx); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (w *word2vec) train() error {
+func (w *word2vec[T]) train(ctx context.Context) error {
 	doc := w.corpus.IndexedDoc()
 	indexPerThread := modelutil.IndexPerThread(
 		w.opts.Goroutines,
 		len(doc),
 	)
 
-	for i := 1; i <= w.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go w.observe(trained, clk)
-
-		sem := semaphore.NewWeighted(int64(w.opts.Goroutines))
-		wg := &sync.WaitGroup{}
+	for i := 1; i <= w.opts.Iter && ctx.Err() == nil; i++ {
+		clk := w.startEpoch()
 
-		for i := 0; i < w.opts.Goroutines; i++ {
-			wg.Add(1)
-			s, e := indexPerThread[i], indexPerThread[i+1]
-			go w.trainPerThread(doc[s:e], trained, sem, wg)
+		chunks := modelutil.Intervals(indexPerThread)
+		if w.opts.Shuffle {
+			chunks = shuffle.Chunks(shuffle.Rand(w.opts.Seed, i), len(doc), w.opts.BatchSize)
 		}
+		modelutil.RunWorkers(w.opts.Goroutines, modelutil.Jobs(chunks), func(t int) func([2]int) {
+			rnd := modelutil.NewRandom(w.opts.Seed, i, t)
+			return func(chunk [2]int) {
+				w.trainPerThread(ctx, doc[chunk[0]:chunk[1]], rnd)
+			}
+		})
 
-		wg.Wait()
-		close(trained)
+		w.endEpoch(clk)
 	}
-	return nil
+	return ctx.Err()
 }
 
-func (w *word2vec) batchTrain() error {
-	for i := 1; i <= w.opts.Iter; i++ {
-		trained, clk := make(chan struct{}), clock.New()
-		go w.observe(trained, clk)
-
-		sem := semaphore.NewWeighted(int64(w.opts.Goroutines))
-		wg := &sync.WaitGroup{}
+func (w *word2vec[T]) batchTrain(ctx context.Context) error {
+	for i := 1; i <= w.opts.Iter && ctx.Err() == nil; i++ {
+		clk := w.startEpoch()
 
 		in := make(chan []int, w.opts.Goroutines)
-		go w.corpus.BatchWords(in, w.opts.BatchSize)
-		for doc := range in {
-			wg.Add(1)
-			go w.trainPerThread(doc, trained, sem, wg)
-		}
+		go w.corpus.BatchWords(ctx, in, w.opts.BatchSize)
+		modelutil.RunWorkers(w.opts.Goroutines, in, func(t int) func([]int) {
+			rnd := modelutil.NewRandom(w.opts.Seed, i, t)
+			return func(doc []int) {
+				w.trainPerThread(ctx, doc, rnd)
+			}
+		})
 
-		wg.Wait()
-		close(trained)
+		w.endEpoch(clk)
 	}
-	return nil
+	return ctx.Err()
 }
 
-func (w *word2vec) trainPerThread(
+func (w *word2vec[T]) trainPerThread(
+	ctx context.Context,
 	doc []int,
-	trained chan struct{},
-	sem *semaphore.Weighted,
-	wg *sync.WaitGroup,
-) error {
-	defer func() {
-		wg.Done()
-		sem.Release(1)
-	}()
-
-	if err := sem.Acquire(context.Background(), 1); err != nil {
-		return err
-	}
-
+	rnd *modelutil.Random,
+) {
+	var (
+		sum float64
+		cnt int
+	)
+	counter := w.progress.NewCounter(w.opts.UpdateLRBatch)
+	lr := w.schedule(w.progress.Ratio())
 	for pos, id := range doc {
-		if w.subsampler.Trial(id) {
-			w.mod.trainOne(doc, pos, w.currentlr, w.param, w.optimizer)
+		if pos%w.opts.BatchSize == 0 && ctx.Err() != nil {
+			break
+		}
+		if w.subsampler.Trial(id, rnd) {
+			l, n := w.mod.trainOne(doc, pos, lr, w.param, w.optimizer, rnd)
+			sum += l
+			cnt += n
+		}
+		if counter.Inc() {
+			lr = w.schedule(w.progress.Ratio())
 		}
-		trained <- struct{}{}
 	}
-
-	return nil
+	counter.Flush()
+	w.loss.Add(sum, cnt)
 }
 
-func (w *word2vec) observe(trained chan struct{}, clk *clock.Clock) {
-	var cnt int
-	for range trained {
-		cnt++
-		if cnt%w.opts.UpdateLRBatch == 0 {
-			if w.currentlr < w.opts.MinLR {
-				w.currentlr = w.opts.MinLR
-			} else {
-				w.currentlr = w.opts.Initlr * (1.0 - float64(cnt)/float64(w.corpus.Len()))
-			}
-		}
+func (w *word2vec[T]) startEpoch() *clock.Clock {
+	clk := clock.New()
+	w.progress.StartEpoch(func(trained int64) {
 		w.verbose.Do(func() {
-			if cnt%w.opts.LogBatch == 0 {
-				fmt.Printf("trained %d words %v\r", cnt, clk.AllElapsed())
-			}
+			fmt.Printf("trained %d words %v loss %f\r", trained, clk.AllElapsed(), w.loss.Current())
 		})
-	}
+	})
+	return clk
+}
+
+func (w *word2vec[T]) endEpoch(clk *clock.Clock) {
+	w.loss.Epoch()
+	w.epochTimes = append(w.epochTimes, clk.AllElapsed())
 	w.verbose.Do(func() {
-		fmt.Printf("trained %d words %v\r\n", cnt, clk.AllElapsed())
+		fmt.Printf("trained %d words %v loss %f\r\n", w.progress.Epoch(), clk.AllElapsed(), w.loss.Last())
 	})
 }
 
-func (w *word2vec) Save(f io.Writer, typ vector.Type) error {
-	return vector.Save(f, w.corpus.Dictionary(), w.WordVector(typ), w.verbose, w.opts.LogBatch)
+func (w *word2vec[T]) Save(f io.Writer, typ vector.Type) error {
+	return w.SaveFormat(f, typ, vector.Text)
 }
 
-func (w *word2vec) WordVector(typ vector.Type) *matrix.Matrix {
-	var mat *matrix.Matrix
-	dic := w.corpus.Dictionary()
-	ng, ok := w.optimizer.(*negativeSampling)
-	if typ == vector.Agg && ok {
-		mat = matrix.New(dic.Len(), w.opts.Dim,
-			func(row int, vec []float64) {
-				for i := 0; i < w.opts.Dim; i++ {
-					vec[i] = w.param.Slice(row)[i] + ng.ctx.Slice(row)[i]
-				}
-			},
-		)
+func (w *word2vec[T]) SaveFormat(f io.Writer, typ vector.Type, format vector.Format) error {
+	mat, err := w.wordVector(typ)
+	if err != nil {
+		return err
+	}
+	return vector.Save(f, w.corpus.Dictionary(), mat, format, w.verbose, w.opts.LogBatch)
+}
+
+func (w *word2vec[T]) Loss() []float64 {
+	return w.loss.History()
+}
+
+func (w *word2vec[T]) Stats() model.Stats {
+	return modelutil.CorpusStats(w.corpus, w.opts.MaxCount, w.opts.MinCount, w.epochTimes)
+}
+
+func (w *word2vec[T]) WordVector(typ vector.Type) (*matrix.Matrix, error) {
+	mat, err := w.wordVector(typ)
+	if err != nil {
+		return nil, err
+	}
+	return matrix.Convert[float64](mat), nil
+}
+
+func (w *word2vec[T]) wordVector(typ vector.Type) (*matrix.MatrixOf[T], error) {
+	if w.param == nil {
+		return nil, model.ErrNotTrained
+	}
+	var (
+		context func(int) []T
+		reason  string
+	)
+	ng, ok := w.optimizer.(*negativeSampling[T])
+	// the output vectors are the context vectors only when they are one per word in the same space.
+	if outputs, dim := outputShape(w.opts); !ok {
+		reason = fmt.Sprintf("%s has the vectors for the inner nodes of huffman tree instead of the words", HierarchicalSoftmax)
+	} else if outputs != 1 || dim != w.opts.Dim {
+		reason = fmt.Sprintf("%s has the output vectors for each position", w.opts.ModelType)
 	} else {
-		mat = matrix.New(dic.Len(), w.opts.Dim,
-			func(row int, vec []float64) {
-				for i := 0; i < w.opts.Dim; i++ {
-					vec[i] = w.param.Slice(row)[i]
-				}
-			},
-		)
+		context = ng.ctx.Slice
 	}
-	return mat
+	return vector.Compose(typ, w.corpus.Dictionary().Len(), w.param.Slice, context, reason)
 }
@@ -0,0 +1,155 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/internal/modeltest"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/num"
+)
+
+func TestTrain(t *testing.T) {
+	doc := modeltest.Corpus(20000, 500)
+	for _, tc := range []struct {
+		inMemory bool
+		shuffle  bool
+	}{
+		{inMemory: true, shuffle: false},
+		{inMemory: true, shuffle: true},
+		{inMemory: false, shuffle: false},
+	} {
+		t.Run(fmt.Sprintf("inMemory=%t,shuffle=%t", tc.inMemory, tc.shuffle), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.DocInMemory = tc.inMemory
+			opts.Shuffle = tc.shuffle
+			opts.Goroutines = 1
+			opts.Iter = 2
+			opts.UpdateLRBatch = 100
+			m, err := NewForOptions(opts)
+			assert.NoError(t, err)
+			modeltest.Train(t, m, doc, opts.Iter, opts.Dim)
+
+			// a single worker draws the same random numbers for the same seed.
+			again, err := NewForOptions(opts)
+			assert.NoError(t, err)
+			assert.NoError(t, again.Train(context.Background(), bytes.NewReader(doc)))
+			assert.Equal(t, m.Loss(), again.Loss())
+		})
+	}
+}
+
+// TestTrainWorkers trains two workers on the halves of the doc which share no words, so that
+// `go test -race` checks the workers (progress, learning rate and loss tracking) without the
+// lock-free updates of the same vectors. Negative sampling is off since it picks any word.
+func TestTrainWorkers(t *testing.T) {
+	doc := strings.Repeat("a b ", 500) + strings.Repeat("c d ", 500)
+	opts := DefaultOptions()
+	opts.DocInMemory = true
+	opts.Goroutines = 2
+	opts.Iter = 2
+	opts.ModelType = SkipGram
+	opts.NegativeSampleSize = 0
+	opts.UpdateLRBatch = 100
+	m, err := NewForOptions(opts)
+	assert.NoError(t, err)
+	modeltest.Train(t, m, []byte(doc), opts.Iter, opts.Dim)
+}
+
+func TestTrainModels(t *testing.T) {
+	// the sentences have the fixed word order, so that every model can fit them.
+	sentences := []string{
+		"the quick brown fox jumps over the lazy dog",
+		"a small red bird sings on the old tree",
+		"my new black car runs on the wide road",
+	}
+	var buf bytes.Buffer
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(&buf, "%s ", sentences[rnd.Intn(len(sentences))])
+	}
+	doc := buf.Bytes()
+	for _, modelType := range []ModelType{Cbow, SkipGram, StructuredSkipGram, CWindow} {
+		for _, optimizerType := range []OptimizerType{NegativeSampling, HierarchicalSoftmax} {
+			t.Run(fmt.Sprintf("%s/%s", modelType, optimizerType), func(t *testing.T) {
+				opts := DefaultOptions()
+				opts.DocInMemory = true
+				opts.Goroutines = 1
+				opts.Iter = 3
+				opts.ModelType = modelType
+				opts.OptimizerType = optimizerType
+				m, err := NewForOptions(opts)
+				assert.NoError(t, err)
+				assert.NoError(t, m.Train(context.Background(), bytes.NewReader(doc)))
+				loss := m.Loss()
+				assert.Len(t, loss, 3)
+				for _, l := range loss {
+					assert.False(t, math.IsNaN(l) || math.IsInf(l, 0))
+				}
+				assert.Less(t, loss[2], loss[0])
+				// the context vectors are one per word only for cbow and skipgram with negative sampling.
+				vec, err := m.WordVector(vector.Sum)
+				if optimizerType == NegativeSampling && (modelType == Cbow || modelType == SkipGram) {
+					assert.NoError(t, err)
+					assert.Equal(t, opts.Dim, vec.Col())
+				} else {
+					assert.Error(t, err)
+				}
+			})
+		}
+	}
+}
+
+func benchmarkTrain[T num.Float](b *testing.B, precision matrix.Precision, goroutines int) {
+	opts := DefaultOptions()
+	opts.Precision = precision
+	opts.DocInMemory = true
+	opts.Dim = 100
+	opts.Goroutines = goroutines
+	opts.Iter = 1
+	doc := modeltest.Corpus(modeltest.BenchWords, 5000)
+
+	modeltest.Benchmark(b, func() (*matrix.MatrixOf[T], error) {
+		m := newWord2vec[T](opts)
+		err := m.Train(context.Background(), bytes.NewReader(doc))
+		return m.param, err
+	})
+}
+
+func BenchmarkTrainFloat32(b *testing.B) {
+	benchmarkTrain[float32](b, matrix.Float32, 1)
+}
+
+func BenchmarkTrainFloat64(b *testing.B) {
+	benchmarkTrain[float64](b, matrix.Float64, 1)
+}
+
+func BenchmarkTrainGoroutines(b *testing.B) {
+	for _, n := range modeltest.BenchGoroutines {
+		b.Run(fmt.Sprintf("goroutines=%d", n), func(b *testing.B) {
+			benchmarkTrain[float32](b, matrix.Float32, n)
+		})
+	}
+}
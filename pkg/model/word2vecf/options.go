@@ -0,0 +1,286 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vecf
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/validate"
+)
+
+var (
+	defaultBatchSize          = 10000
+	defaultDim                = 10
+	defaultDocInMemory        = false
+	defaultGoroutines         = runtime.NumCPU()
+	defaultInitlr             = 0.025
+	defaultIter               = 15
+	defaultLRSchedule         = schedule.Linear
+	defaultLogBatch           = 100000
+	defaultMaxCount           = -1
+	defaultMinCount           = 5
+	defaultMinLR              = defaultInitlr * 1.0e-4
+	defaultNegativeSampleSize = 5
+	defaultPrecision          = matrix.Float64
+	defaultSeed               = int64(1)
+	defaultSmooth             = 0.75
+	defaultStepDecay          = 0.5
+	defaultSubsampleThreshold = 1.0e-3
+	defaultTimeBudget         = time.Duration(0)
+	defaultToLower            = false
+	defaultUpdateLRBatch      = 100000
+	defaultVerbose            = false
+	defaultWarmupRatio        = 0.1
+)
+
+type Options struct {
+	BatchSize          int
+	Dim                int
+	DocInMemory        bool
+	Goroutines         int
+	Initlr             float64
+	Iter               int
+	LRSchedule         schedule.Type
+	LogBatch           int
+	MaxCount           int
+	MinCount           int
+	MinLR              float64
+	NegativeSampleSize int
+	Precision          matrix.Precision
+	Seed               int64
+	Smooth             float64
+	StepDecay          float64
+	SubsampleThreshold float64
+	TimeBudget         time.Duration
+	ToLower            bool
+	UpdateLRBatch      int
+	Verbose            bool
+	WarmupRatio        float64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		BatchSize:          defaultBatchSize,
+		Dim:                defaultDim,
+		DocInMemory:        defaultDocInMemory,
+		Goroutines:         defaultGoroutines,
+		Initlr:             defaultInitlr,
+		Iter:               defaultIter,
+		LRSchedule:         defaultLRSchedule,
+		LogBatch:           defaultLogBatch,
+		MaxCount:           defaultMaxCount,
+		MinCount:           defaultMinCount,
+		MinLR:              defaultMinLR,
+		NegativeSampleSize: defaultNegativeSampleSize,
+		Precision:          defaultPrecision,
+		Seed:               defaultSeed,
+		Smooth:             defaultSmooth,
+		StepDecay:          defaultStepDecay,
+		SubsampleThreshold: defaultSubsampleThreshold,
+		TimeBudget:         defaultTimeBudget,
+		ToLower:            defaultToLower,
+		UpdateLRBatch:      defaultUpdateLRBatch,
+		Verbose:            defaultVerbose,
+		WarmupRatio:        defaultWarmupRatio,
+	}
+}
+
+// Validate reports all invalid values of Options at once.
+func (opts Options) Validate() error {
+	v := validate.New()
+	v.Positive("BatchSize", opts.BatchSize)
+	v.Positive("Dim", opts.Dim)
+	v.Positive("Goroutines", opts.Goroutines)
+	v.PositiveFloat("Initlr", opts.Initlr)
+	v.Positive("Iter", opts.Iter)
+	v.OneOf("LRSchedule", opts.LRSchedule, schedule.Linear, schedule.Constant, schedule.Cosine, schedule.Step, schedule.Warmup)
+	v.Positive("LogBatch", opts.LogBatch)
+	v.NonNegativeFloat("MinLR", opts.MinLR)
+	v.Check(opts.MinLR <= opts.Initlr, "MinLR", opts.MinLR, fmt.Sprintf("must be <= Initlr=%v", opts.Initlr))
+	v.NonNegative("NegativeSampleSize", opts.NegativeSampleSize)
+	v.OneOf("Precision", opts.Precision, matrix.Float64, matrix.Float32)
+	v.NonNegativeFloat("Smooth", opts.Smooth)
+	v.Range("StepDecay", opts.StepDecay, 0, 1)
+	v.NonNegativeFloat("SubsampleThreshold", opts.SubsampleThreshold)
+	v.Check(opts.TimeBudget >= 0, "TimeBudget", opts.TimeBudget, "must be >= 0")
+	v.Positive("UpdateLRBatch", opts.UpdateLRBatch)
+	v.Check(0 <= opts.WarmupRatio && opts.WarmupRatio < 1, "WarmupRatio", opts.WarmupRatio, "must be in [0, 1)")
+	return v.Err()
+}
+
+func LoadForCmd(cmd *cobra.Command, opts *Options) {
+	cmd.Flags().IntVar(&opts.BatchSize, "batch", defaultBatchSize, "batch size to train (> 0)")
+	cmd.Flags().IntVarP(&opts.Dim, "dim", "d", defaultDim, "dimension for word vector (> 0)")
+	cmd.Flags().IntVar(&opts.Goroutines, "goroutines", defaultGoroutines, "number of goroutine (> 0)")
+	cmd.Flags().BoolVar(&opts.DocInMemory, "in-memory", defaultDocInMemory, "whether to store the pairs in memory")
+	cmd.Flags().Float64Var(&opts.Initlr, "initlr", defaultInitlr, "initial learning rate (> 0)")
+	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration (> 0)")
+	cmd.Flags().StringVar(&opts.LRSchedule, "lr-schedule", defaultLRSchedule, fmt.Sprintf("learning rate schedule over total training progress. One of: %s|%s|%s|%s|%s", schedule.Linear, schedule.Constant, schedule.Cosine, schedule.Step, schedule.Warmup))
+	cmd.Flags().IntVar(&opts.LogBatch, "log-batch", defaultLogBatch, "batch size to log for counting pairs (> 0)")
+	cmd.Flags().IntVar(&opts.MaxCount, "max-count", defaultMaxCount, "upper limit to filter words and contexts")
+	cmd.Flags().IntVar(&opts.MinCount, "min-count", defaultMinCount, "lower limit to filter words and contexts")
+	cmd.Flags().Float64Var(&opts.MinLR, "min-lr", defaultMinLR, "lower limit of learning rate (in [0, initlr])")
+	cmd.Flags().IntVar(&opts.NegativeSampleSize, "sample", defaultNegativeSampleSize, "negative sample size (>= 0)")
+	cmd.Flags().StringVar(&opts.Precision, "precision", defaultPrecision, fmt.Sprintf("floating point type to store parameters. One of: %s|%s", matrix.Float64, matrix.Float32))
+	cmd.Flags().Int64Var(&opts.Seed, "seed", defaultSeed, "random seed for initialization and sampling")
+	cmd.Flags().Float64Var(&opts.Smooth, "smooth", defaultSmooth, "exponent of context frequencies for the negative sampling distribution (>= 0)")
+	cmd.Flags().Float64Var(&opts.StepDecay, "step-decay", defaultStepDecay, "factor to multiply learning rate at each epoch (for step schedule only; in [0, 1])")
+	cmd.Flags().Float64Var(&opts.SubsampleThreshold, "threshold", defaultSubsampleThreshold, "threshold for subsampling words (>= 0)")
+	cmd.Flags().DurationVar(&opts.TimeBudget, "time-budget", defaultTimeBudget, "wall-clock budget for training, e.g. 30m (no limit if zero; >= 0)")
+	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on corpus convert to lowercase or not")
+	cmd.Flags().IntVar(&opts.UpdateLRBatch, "update-lr-batch", defaultUpdateLRBatch, "batch size to update learning rate (> 0)")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
+	cmd.Flags().Float64Var(&opts.WarmupRatio, "warmup-ratio", defaultWarmupRatio, "ratio of training to increase learning rate linearly (for warmup schedule only; in [0, 1))")
+}
+
+type ModelOption func(*Options)
+
+func BatchSize(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.BatchSize = v
+	})
+}
+
+func DocInMemory() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.DocInMemory = true
+	})
+}
+
+func Goroutines(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Goroutines = v
+	})
+}
+
+func Dim(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Dim = v
+	})
+}
+
+func Initlr(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Initlr = v
+	})
+}
+
+func Iter(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Iter = v
+	})
+}
+
+func LRSchedule(v schedule.Type) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LRSchedule = v
+	})
+}
+
+func LogBatch(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.LogBatch = v
+	})
+}
+
+func MaxCount(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MaxCount = v
+	})
+}
+
+func MinCount(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MinCount = v
+	})
+}
+
+func MinLR(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.MinLR = v
+	})
+}
+
+func NegativeSampleSize(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.NegativeSampleSize = v
+	})
+}
+
+func Precision(typ matrix.Precision) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Precision = typ
+	})
+}
+
+func Seed(v int64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Seed = v
+	})
+}
+
+func Smooth(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Smooth = v
+	})
+}
+
+func StepDecay(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.StepDecay = v
+	})
+}
+
+func SubsampleThreshold(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.SubsampleThreshold = v
+	})
+}
+
+func TimeBudget(v time.Duration) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.TimeBudget = v
+	})
+}
+
+func ToLower() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.ToLower = true
+	})
+}
+
+func UpdateLRBatch(v int) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.UpdateLRBatch = v
+	})
+}
+
+func Verbose() ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.Verbose = true
+	})
+}
+
+func WarmupRatio(v float64) ModelOption {
+	return ModelOption(func(opts *Options) {
+		opts.WarmupRatio = v
+	})
+}
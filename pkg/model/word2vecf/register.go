@@ -0,0 +1,32 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vecf
+
+import (
+	"github.com/ynqa/wego/pkg/model"
+)
+
+// Registration is the entry of word2vecf in the model registry.
+var Registration = model.Registration{
+	Name:  "word2vecf",
+	Short: "Word2Vecf: Skip-gram with negative sampling on arbitrary word-context pairs",
+	Factory: func() model.Factory {
+		return model.NewFactory(DefaultOptions, LoadForCmd, NewForOptions)
+	},
+}
+
+func init() {
+	model.Register(Registration)
+}
@@ -0,0 +1,327 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vecf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ynqa/wego/pkg/corpus/cpsutil"
+	"github.com/ynqa/wego/pkg/corpus/pairs"
+	"github.com/ynqa/wego/pkg/model"
+	"github.com/ynqa/wego/pkg/model/modelutil"
+	"github.com/ynqa/wego/pkg/model/modelutil/loss"
+	"github.com/ynqa/wego/pkg/model/modelutil/matrix"
+	"github.com/ynqa/wego/pkg/model/modelutil/progress"
+	"github.com/ynqa/wego/pkg/model/modelutil/schedule"
+	"github.com/ynqa/wego/pkg/model/modelutil/subsample"
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+// word2vecf trains skip-gram with negative sampling on the explicit (word, context) pairs
+// instead of the linear windows (Levy and Goldberg 2014).
+type word2vecf[T num.Float] struct {
+	opts Options
+
+	corpus *pairs.Corpus
+
+	// param stores the word vectors, and ctx stores the context vectors.
+	param      *matrix.MatrixOf[T]
+	ctx        *matrix.MatrixOf[T]
+	negatives  []float64
+	subsampler *subsample.Subsampler
+	schedule   schedule.Schedule
+	progress   *progress.Progress
+	loss       *loss.Loss
+	epochTimes []time.Duration
+
+	verbose *verbose.Verbose
+}
+
+func New(opts ...ModelOption) (model.Model, error) {
+	options := DefaultOptions()
+	for _, fn := range opts {
+		fn(&options)
+	}
+
+	return NewForOptions(options)
+}
+
+func NewForOptions(opts Options) (model.Model, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.Precision == matrix.Float32 {
+		return newWord2vecf[float32](opts), nil
+	}
+	return newWord2vecf[float64](opts), nil
+}
+
+func newWord2vecf[T num.Float](opts Options) *word2vecf[T] {
+	v := verbose.New(opts.Verbose)
+	return &word2vecf[T]{
+		opts: opts,
+
+		loss: loss.New(),
+
+		verbose: v,
+	}
+}
+
+// Train reads the pairs from r, one pair per line, as `<word> <context>`.
+func (w *word2vecf[T]) Train(ctx context.Context, r io.ReadSeeker) error {
+	if w.opts.TimeBudget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.opts.TimeBudget)
+		defer cancel()
+	}
+
+	w.corpus = pairs.New(r, w.opts.DocInMemory, w.opts.ToLower, w.opts.MaxCount, w.opts.MinCount)
+	if err := w.corpus.Load(ctx, w.verbose, w.opts.LogBatch); err != nil {
+		return err
+	}
+
+	words, contexts, dim := w.corpus.WordDictionary(), w.corpus.ContextDictionary(), w.opts.Dim
+
+	rnd := rand.New(rand.NewSource(w.opts.Seed))
+	w.param = matrix.New(
+		words.Len(),
+		dim,
+		func(_ int, vec []T) {
+			for i := 0; i < dim; i++ {
+				vec[i] = T((rnd.Float64() - 0.5) / float64(dim))
+			}
+		},
+	)
+	w.ctx = matrix.New(
+		contexts.Len(),
+		dim,
+		func(_ int, vec []T) {
+			for i := 0; i < dim; i++ {
+				vec[i] = T((rnd.Float64() - 0.5) / float64(dim))
+			}
+		},
+	)
+
+	sched, err := schedule.New(w.opts.LRSchedule, schedule.Config{
+		Initlr:      w.opts.Initlr,
+		MinLR:       w.opts.MinLR,
+		Iter:        w.opts.Iter,
+		WarmupRatio: w.opts.WarmupRatio,
+		StepDecay:   w.opts.StepDecay,
+	})
+	if err != nil {
+		return err
+	}
+	w.schedule = sched
+	w.progress = progress.New(w.corpus.FilteredLen()*w.opts.Iter, w.opts.LogBatch)
+
+	w.subsampler = subsample.New(words, w.opts.SubsampleThreshold)
+	w.negatives = w.makeNegatives()
+
+	return w.train(ctx)
+}
+
+// makeNegatives returns the cumulative distribution of the contexts by frequency^Smooth.
+// The filtered contexts are never sampled.
+func (w *word2vecf[T]) makeNegatives() []float64 {
+	contexts := w.corpus.ContextDictionary()
+	filters := cpsutil.Filters{
+		cpsutil.MaxCount(w.opts.MaxCount),
+		cpsutil.MinCount(w.opts.MinCount),
+	}
+	res := make([]float64, contexts.Len())
+	var sum float64
+	for i := range res {
+		if !filters.Any(i, contexts) {
+			sum += math.Pow(float64(contexts.IDFreq(i)), w.opts.Smooth)
+		}
+		res[i] = sum
+	}
+	return res
+}
+
+// sampleNegative picks x in (0, sum] by rnd so that the contexts with zero weight are never found.
+func (w *word2vecf[T]) sampleNegative(rnd *modelutil.Random) int {
+	sum := w.negatives[len(w.negatives)-1]
+	x := float64(rnd.Next(1<<53)+1) / (1 << 53) * sum
+	return sort.SearchFloat64s(w.negatives, x)
+}
+
+func (w *word2vecf[T]) train(ctx context.Context) error {
+	for i := 1; i <= w.opts.Iter && ctx.Err() == nil; i++ {
+		clk := w.startEpoch()
+
+		in := make(chan []int, w.opts.Goroutines)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- w.corpus.BatchPairs(ctx, in, w.opts.BatchSize)
+		}()
+		modelutil.RunWorkers(w.opts.Goroutines, in, func(t int) func([]int) {
+			rnd := modelutil.NewRandom(w.opts.Seed, i, t)
+			tmp := make([]T, w.opts.Dim)
+			return func(ids []int) {
+				w.trainPerThread(ctx, ids, tmp, rnd)
+			}
+		})
+
+		if err := <-errCh; err != nil {
+			return err
+		}
+		w.endEpoch(clk)
+	}
+	return ctx.Err()
+}
+
+func (w *word2vecf[T]) trainPerThread(
+	ctx context.Context,
+	ids []int,
+	tmp []T,
+	rnd *modelutil.Random,
+) {
+	var (
+		sum float64
+		cnt int
+	)
+	counter := w.progress.NewCounter(w.opts.UpdateLRBatch)
+	lr := w.schedule(w.progress.Ratio())
+	for i := 0; i < len(ids); i += 2 {
+		if (i/2)%w.opts.BatchSize == 0 && ctx.Err() != nil {
+			break
+		}
+		if w.subsampler.Trial(ids[i], rnd) {
+			sum += w.trainOne(ids[i], ids[i+1], lr, tmp, rnd)
+			cnt++
+		}
+		if counter.Inc() {
+			lr = w.schedule(w.progress.Ratio())
+		}
+	}
+	counter.Flush()
+	w.loss.Add(sum, cnt)
+}
+
+// trainOne predicts the context by the word vector against NegativeSampleSize negative contexts.
+func (w *word2vecf[T]) trainOne(word, context int, lr float64, tmp []T, rnd *modelutil.Random) float64 {
+	vec := w.param.Slice(word)
+	vecmath.Zero(tmp)
+	var (
+		label  int
+		picked int
+		loss   float64
+	)
+	for n := -1; n < w.opts.NegativeSampleSize; n++ {
+		if n == -1 {
+			label = 1
+			picked = context
+		} else {
+			label = 0
+			picked = w.sampleNegative(rnd)
+			if picked == context {
+				continue
+			}
+		}
+		out := w.ctx.Slice(picked)
+		x := float64(vecmath.Dot(out, vec))
+		g := (float64(label) - sigmoid(x)) * lr
+		loss += modelutil.LogLoss(label, x)
+		vecmath.Axpy(T(g), out, tmp)
+		vecmath.Axpy(T(g), vec, out)
+	}
+	vecmath.Axpy(1, tmp, vec)
+	return loss
+}
+
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+func (w *word2vecf[T]) startEpoch() *clock.Clock {
+	clk := clock.New()
+	w.progress.StartEpoch(func(trained int64) {
+		w.verbose.Do(func() {
+			fmt.Printf("trained %d pairs %v loss %f\r", trained, clk.AllElapsed(), w.loss.Current())
+		})
+	})
+	return clk
+}
+
+func (w *word2vecf[T]) endEpoch(clk *clock.Clock) {
+	w.loss.Epoch()
+	w.epochTimes = append(w.epochTimes, clk.AllElapsed())
+	w.verbose.Do(func() {
+		fmt.Printf("trained %d pairs %v loss %f\r\n", w.progress.Epoch(), clk.AllElapsed(), w.loss.Last())
+	})
+}
+
+// Save writes the word vectors. The context vectors are not added for vector.Agg
+// because they are on the other vocabulary.
+func (w *word2vecf[T]) Save(f io.Writer, typ vector.Type) error {
+	return w.SaveFormat(f, typ, vector.Text)
+}
+
+func (w *word2vecf[T]) SaveFormat(f io.Writer, typ vector.Type, format vector.Format) error {
+	mat, err := w.wordVector(typ)
+	if err != nil {
+		return err
+	}
+	return vector.Save(f, w.corpus.WordDictionary(), mat, format, w.verbose, w.opts.LogBatch)
+}
+
+func (w *word2vecf[T]) Loss() []float64 {
+	return w.loss.History()
+}
+
+func (w *word2vecf[T]) Stats() model.Stats {
+	if w.corpus == nil {
+		return model.Stats{}
+	}
+	words := w.corpus.WordDictionary()
+	filters := cpsutil.Filters{
+		cpsutil.MaxCount(w.opts.MaxCount),
+		cpsutil.MinCount(w.opts.MinCount),
+	}
+	return model.Stats{
+		Vocab:          words.Len(),
+		FilteredVocab:  filters.Vocab(words),
+		Tokens:         w.corpus.Len(),
+		FilteredTokens: w.corpus.FilteredLen(),
+		EpochTimes:     append([]time.Duration(nil), w.epochTimes...),
+	}
+}
+
+func (w *word2vecf[T]) WordVector(typ vector.Type) (*matrix.Matrix, error) {
+	mat, err := w.wordVector(typ)
+	if err != nil {
+		return nil, err
+	}
+	return matrix.Convert[float64](mat), nil
+}
+
+func (w *word2vecf[T]) wordVector(typ vector.Type) (*matrix.MatrixOf[T], error) {
+	if w.param == nil {
+		return nil, model.ErrNotTrained
+	}
+	return vector.Compose(typ, w.corpus.WordDictionary().Len(), w.param.Slice, nil, "the contexts have their own vocabulary")
+}
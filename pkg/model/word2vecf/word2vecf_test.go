@@ -0,0 +1,67 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package word2vecf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/vector"
+)
+
+func TestTrain(t *testing.T) {
+	// each word has the contexts of its own group, so that the pairs can be fitted.
+	var buf bytes.Buffer
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		w := rnd.Intn(30)
+		fmt.Fprintf(&buf, "w%d c%d/%d\n", w, w%3, rnd.Intn(5))
+	}
+	doc := buf.Bytes()
+
+	for _, inMemory := range []bool{false, true} {
+		t.Run(fmt.Sprintf("in-memory=%v", inMemory), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.DocInMemory = inMemory
+			opts.Goroutines = 1
+			opts.Iter = 3
+			m, err := NewForOptions(opts)
+			assert.NoError(t, err)
+			assert.NoError(t, m.Train(context.Background(), bytes.NewReader(doc)))
+			loss := m.Loss()
+			assert.Len(t, loss, 3)
+			assert.Less(t, loss[2], loss[0])
+			vec, err := m.WordVector(vector.Word)
+			assert.NoError(t, err)
+			assert.Equal(t, 30, vec.Row())
+			assert.Equal(t, opts.Dim, vec.Col())
+			_, err = m.WordVector(vector.Sum)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTrainInvalidPair(t *testing.T) {
+	m, err := New()
+	assert.NoError(t, err)
+	err = m.Train(context.Background(), strings.NewReader("a b\nc d e\n"))
+	assert.EqualError(t, err, "line 2: expected <word> <context> but got 3 fields")
+}
@@ -0,0 +1,97 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrofit
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/ynqa/wego/pkg/model/modelutil/validate"
+)
+
+var (
+	defaultAlpha   = 1.0
+	defaultBeta    = 1.0
+	defaultIter    = 10
+	defaultToLower = false
+	defaultVerbose = false
+)
+
+type Options struct {
+	Alpha   float64
+	Beta    float64
+	Iter    int
+	ToLower bool
+	Verbose bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Alpha:   defaultAlpha,
+		Beta:    defaultBeta,
+		Iter:    defaultIter,
+		ToLower: defaultToLower,
+		Verbose: defaultVerbose,
+	}
+}
+
+// Validate reports all invalid values of Options at once.
+func (opts Options) Validate() error {
+	v := validate.New()
+	v.NonNegativeFloat("Alpha", opts.Alpha)
+	v.NonNegativeFloat("Beta", opts.Beta)
+	v.Check(opts.Alpha+opts.Beta > 0, "Alpha", opts.Alpha, "Alpha and Beta must not be both zero")
+	v.Positive("Iter", opts.Iter)
+	return v.Err()
+}
+
+func LoadForCmd(cmd *cobra.Command, opts *Options) {
+	cmd.Flags().Float64Var(&opts.Alpha, "alpha", defaultAlpha, "weight to keep the original vector")
+	cmd.Flags().Float64Var(&opts.Beta, "beta", defaultBeta, "weight to pull the vector to the related words, divided by the number of them")
+	cmd.Flags().IntVar(&opts.Iter, "iter", defaultIter, "number of iteration")
+	cmd.Flags().BoolVar(&opts.ToLower, "to-lower", defaultToLower, "whether the words on lexicon convert to lowercase or not")
+	cmd.Flags().BoolVar(&opts.Verbose, "verbose", defaultVerbose, "verbose mode")
+}
+
+type Option func(*Options)
+
+func Alpha(v float64) Option {
+	return Option(func(opts *Options) {
+		opts.Alpha = v
+	})
+}
+
+func Beta(v float64) Option {
+	return Option(func(opts *Options) {
+		opts.Beta = v
+	})
+}
+
+func Iter(v int) Option {
+	return Option(func(opts *Options) {
+		opts.Iter = v
+	})
+}
+
+func ToLower() Option {
+	return Option(func(opts *Options) {
+		opts.ToLower = true
+	})
+}
+
+func Verbose() Option {
+	return Option(func(opts *Options) {
+		opts.Verbose = true
+	})
+}
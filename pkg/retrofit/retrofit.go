@@ -0,0 +1,156 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrofit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/clock"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+	"github.com/ynqa/wego/pkg/util/verbose"
+)
+
+// Lexicon maps the word to its related words, e.g. synonyms from WordNet or PPDB.
+type Lexicon map[string][]string
+
+// LoadLexicon reads the lexicon graph, one word and its related words per line separated by spaces:
+//
+//	<word> <related_1> <related_2> ...
+//
+// The relations are used as they are written; for the symmetric relation, both directions must be listed.
+func LoadLexicon(r io.Reader) (Lexicon, error) {
+	lex := make(Lexicon)
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		words := strings.Fields(s.Text())
+		if len(words) == 0 {
+			continue
+		}
+		lex[words[0]] = append(lex[words[0]], words[1:]...)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return lex, nil
+}
+
+// Retrofit pulls the vectors of the words to the vectors of their related words on lex,
+// while keeping them close to the original vectors (Faruqui et al. 2015).
+// Each iteration updates the vector q_i of the word i in place by
+//
+//	q_i = (alpha * orig_i + beta/|N(i)| * sum_{j in N(i)} q_j) / (alpha + beta)
+//
+// where N(i) is the related words of i which have the vectors. The words on lex are converted
+// to lowercase by ToLower before looking up embs.
+// The words without the related words keep the original vectors. embs is not modified.
+func Retrofit(embs embedding.Embeddings, lex Lexicon, opts ...Option) (embedding.Embeddings, error) {
+	options := DefaultOptions()
+	for _, fn := range opts {
+		fn(&options)
+	}
+	return RetrofitForOptions(embs, lex, options)
+}
+
+func RetrofitForOptions(embs embedding.Embeddings, lex Lexicon, opts Options) (embedding.Embeddings, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if err := embs.Validate(); err != nil {
+		return nil, err
+	}
+	verbose := verbose.New(opts.Verbose)
+
+	index := make(map[string]int, len(embs))
+	for i, emb := range embs {
+		index[emb.Word] = i
+	}
+	neighbors := make(map[int][]int)
+	for word, related := range lex {
+		if opts.ToLower {
+			word = strings.ToLower(word)
+		}
+		i, ok := index[word]
+		if !ok {
+			continue
+		}
+		for _, r := range related {
+			if opts.ToLower {
+				r = strings.ToLower(r)
+			}
+			if j, ok := index[r]; ok && j != i {
+				neighbors[i] = append(neighbors[i], j)
+			}
+		}
+	}
+
+	res := make(embedding.Embeddings, len(embs))
+	for i, emb := range embs {
+		vec := make([]float64, len(emb.Vector))
+		copy(vec, emb.Vector)
+		res[i] = embedding.Embedding{
+			Word:   emb.Word,
+			Dim:    emb.Dim,
+			Vector: vec,
+		}
+	}
+
+	// visit the words in the order of embs so that the result is deterministic.
+	words := make([]int, 0, len(neighbors))
+	for i := range embs {
+		if len(neighbors[i]) > 0 {
+			words = append(words, i)
+		}
+	}
+
+	clk := clock.New()
+	var tmp []float64
+	if len(embs) > 0 {
+		tmp = make([]float64, embs[0].Dim)
+	}
+	for iter := 1; iter <= opts.Iter; iter++ {
+		var diff float64
+		for _, i := range words {
+			ns := neighbors[i]
+			vecmath.Zero(tmp)
+			for _, j := range ns {
+				vecmath.Axpy(1, res[j].Vector, tmp)
+			}
+			vecmath.Scale(opts.Beta/float64(len(ns)), tmp)
+			vecmath.Axpy(opts.Alpha, embs[i].Vector, tmp)
+			vecmath.Scale(1/(opts.Alpha+opts.Beta), tmp)
+
+			vec := res[i].Vector
+			for k := range vec {
+				d := tmp[k] - vec[k]
+				diff += d * d
+			}
+			copy(vec, tmp)
+		}
+		verbose.Do(func() {
+			fmt.Printf("retrofitted %d words at iteration %d %v change %f\n", len(words), iter, clk.AllElapsed(), diff)
+		})
+	}
+
+	for i := range res {
+		res[i].Norm = embutil.Norm(res[i].Vector)
+	}
+	return res, nil
+}
@@ -0,0 +1,51 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retrofit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+func TestRetrofit(t *testing.T) {
+	embs, err := embedding.Load(strings.NewReader("a 1 0\nb 0 1\nc 0 0\nd 2 2\n"))
+	assert.NoError(t, err)
+	lex, err := LoadLexicon(strings.NewReader("A b unknown\nB a\n\nc\n"))
+	assert.NoError(t, err)
+
+	res, err := Retrofit(embs, lex, Iter(1), ToLower())
+	assert.NoError(t, err)
+	// a = (a + b) / 2, then b = (b + a') / 2 with the updated a.
+	assert.InDeltaSlice(t, []float64{0.5, 0.5}, res[0].Vector, 1e-9)
+	assert.InDeltaSlice(t, []float64{0.25, 0.75}, res[1].Vector, 1e-9)
+	assert.Equal(t, []float64{0, 0}, res[2].Vector)
+	assert.Equal(t, []float64{2, 2}, res[3].Vector)
+	assert.InDelta(t, 0.790569, res[1].Norm, 1e-6)
+	// the original vectors are kept.
+	assert.Equal(t, []float64{1, 0}, embs[0].Vector)
+
+	res, err = Retrofit(embs, lex, Iter(100), Alpha(0), ToLower())
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, res[0].Vector, res[1].Vector, 1e-9)
+}
+
+func TestRetrofitInvalidOptions(t *testing.T) {
+	_, err := Retrofit(nil, nil, Alpha(0), Beta(0))
+	assert.Error(t, err)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/ynqa/wego/pkg/embedding"
 	"github.com/ynqa/wego/pkg/embedding/embutil"
 	"github.com/ynqa/wego/pkg/search/searchutil"
+	"github.com/ynqa/wego/pkg/util/num"
 )
 
 // Neighbor stores the word with cosine similarity value on the target.
@@ -52,21 +53,28 @@ func (neighbors Neighbors) Describe() {
 	writer.Render()
 }
 
-type Searcher struct {
-	Items embedding.Embeddings
+// SearcherOf looks up the neighbors over the embeddings whose elements are stored as T.
+type SearcherOf[T num.Float] struct {
+	Items embedding.EmbeddingsOf[T]
 }
 
+type Searcher = SearcherOf[float64]
+
 func New(embs ...embedding.Embedding) (*Searcher, error) {
-	if err := embedding.Embeddings(embs).Validate(); err != nil {
+	return NewOf(embs...)
+}
+
+func NewOf[T num.Float](embs ...embedding.EmbeddingOf[T]) (*SearcherOf[T], error) {
+	if err := embedding.EmbeddingsOf[T](embs).Validate(); err != nil {
 		return nil, err
 	}
-	return &Searcher{
+	return &SearcherOf[T]{
 		Items: embs,
 	}, nil
 }
 
-func (s *Searcher) SearchInternal(word string, k int) (Neighbors, error) {
-	var q embedding.Embedding
+func (s *SearcherOf[T]) SearchInternal(word string, k int) (Neighbors, error) {
+	var q embedding.EmbeddingOf[T]
 	for _, item := range s.Items {
 		if item.Word == word {
 			q = item
@@ -84,14 +92,14 @@ func (s *Searcher) SearchInternal(word string, k int) (Neighbors, error) {
 	return neighbors, nil
 }
 
-func (s *Searcher) SearchVector(query []float64, k int) (Neighbors, error) {
-	return s.Search(embedding.Embedding{
+func (s *SearcherOf[T]) SearchVector(query []T, k int) (Neighbors, error) {
+	return s.Search(embedding.EmbeddingOf[T]{
 		Vector: query,
 		Norm:   embutil.Norm(query),
 	}, k)
 }
 
-func (s *Searcher) Search(query embedding.Embedding, k int, ignoreWord ...string) (Neighbors, error) {
+func (s *SearcherOf[T]) Search(query embedding.EmbeddingOf[T], k int, ignoreWord ...string) (Neighbors, error) {
 	neighbors := make(Neighbors, k)
 
 	// Map to quickly check if a word is to be ignored.
@@ -14,13 +14,14 @@
 
 package searchutil
 
-func Cosine(v1, v2 []float64, n1, n2 float64) float64 {
+import (
+	"github.com/ynqa/wego/pkg/util/num"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+func Cosine[T num.Float](v1, v2 []T, n1, n2 float64) float64 {
 	if n1 == 0 || n2 == 0 {
 		return 0
 	}
-	var dot float64
-	for i := range v1 {
-		dot += v1[i] * v2[i]
-	}
-	return dot / n1 / n2
+	return float64(vecmath.Dot(v1, v2)) / n1 / n2
 }
@@ -0,0 +1,115 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ynqa/wego/pkg/evaluate"
+)
+
+// Score is the evaluation of the vectors on a dataset.
+type Score struct {
+	Dataset string
+	evaluate.Result
+}
+
+// Result is the outcome of a run for a configuration.
+type Result struct {
+	Run          int
+	Config       Config
+	Output       string
+	FinalLoss    *float64
+	TrainSeconds float64
+	Stopped      bool
+	Scores       []Score
+	Err          error
+}
+
+// Table returns the header and the rows of results, which have the same params and datasets.
+// Each dataset has the columns of the score and the coverage.
+func Table(results []Result) ([]string, [][]string) {
+	if len(results) == 0 {
+		return nil, nil
+	}
+	header := []string{"run"}
+	for _, s := range results[0].Config {
+		header = append(header, s.Name)
+	}
+	header = append(header, "loss", "seconds")
+	var datasets []string
+	for _, r := range results {
+		if r.Err == nil && len(r.Scores) > 0 {
+			for _, s := range r.Scores {
+				datasets = append(datasets, s.Dataset)
+				header = append(header, s.Dataset, s.Dataset+" coverage")
+			}
+			break
+		}
+	}
+	header = append(header, "output", "error")
+
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		row := []string{strconv.Itoa(r.Run)}
+		for _, s := range r.Config {
+			row = append(row, s.Value)
+		}
+		if r.FinalLoss != nil {
+			row = append(row, fmt.Sprintf("%f", *r.FinalLoss))
+		} else {
+			row = append(row, "")
+		}
+		row = append(row, fmt.Sprintf("%.3f", r.TrainSeconds))
+		for j := range datasets {
+			if j < len(r.Scores) {
+				row = append(row, fmt.Sprintf("%.4f", r.Scores[j].Score), fmt.Sprintf("%.4f", r.Scores[j].Coverage()))
+			} else {
+				row = append(row, "", "")
+			}
+		}
+		var msg string
+		switch {
+		case r.Err != nil:
+			msg = r.Err.Error()
+		case r.Stopped:
+			msg = "stopped before completion"
+		}
+		row = append(row, r.Output, msg)
+		rows[i] = row
+	}
+	return header, rows
+}
+
+// WriteTSV writes the table of results into w as tab separated values.
+func WriteTSV(w io.Writer, results []Result) error {
+	header, rows := Table(results)
+	if header == nil {
+		return nil
+	}
+	clean := strings.NewReplacer("\t", " ", "\n", " ")
+	for _, row := range append([][]string{header}, rows...) {
+		for i, v := range row {
+			row[i] = clean.Replace(v)
+		}
+		if _, err := io.WriteString(w, strings.Join(row, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/evaluate"
+)
+
+func TestWriteTSV(t *testing.T) {
+	loss := 0.5
+	results := []Result{
+		{
+			Run:          1,
+			Config:       Config{{Name: "dim", Value: "50"}},
+			Output:       "sweep/run-001.txt",
+			FinalLoss:    &loss,
+			TrainSeconds: 1.5,
+			Scores:       []Score{{Dataset: "ws353", Result: evaluate.Result{Score: 0.6, Found: 3, Total: 4}}},
+		},
+		{
+			Run:    2,
+			Config: Config{{Name: "dim", Value: "0"}},
+			Output: "sweep/run-002.txt",
+			Err:    errors.New("invalid options:\n\tDim=0"),
+		},
+	}
+	var buf bytes.Buffer
+	assert.NoError(t, WriteTSV(&buf, results))
+	assert.Equal(t, "run\tdim\tloss\tseconds\tws353\tws353 coverage\toutput\terror\n"+
+		"1\t50\t0.500000\t1.500\t0.6000\t0.7500\tsweep/run-001.txt\t\n"+
+		"2\t0\t\t0.000\t\t\tsweep/run-002.txt\tinvalid options:  Dim=0\n", buf.String())
+}
@@ -0,0 +1,195 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SearchType is the way to draw the configurations from the search space.
+type SearchType = string
+
+const (
+	Grid   SearchType = "grid"
+	Random SearchType = "random"
+)
+
+func invalidSearchTypeError(typ SearchType) error {
+	return errors.Errorf("invalid search type: %s not in %s|%s", typ, Grid, Random)
+}
+
+// Param is the values of an option to search: the choices of Values, or the range from Min
+// to Max, which is sampled uniformly, or log-uniformly if Log, by random search.
+type Param struct {
+	Name   string
+	Values []string
+
+	Range    bool
+	Min, Max float64
+	Int      bool
+	Log      bool
+}
+
+// Setting is the value of an option in a configuration.
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// Config is the values of the params for a run, in the order of the params.
+type Config []Setting
+
+// ParseParams parses the search space, each of which is `<name>=<v1>,<v2>,...` for the choices,
+// or `<name>=<min>..<max>` for the range, with the suffix `:log` for the log scale. The ranges
+// of integers draw integers. An item can hold several params, e.g. `dim=50,100,window=5,10`.
+func ParseParams(specs []string) ([]Param, error) {
+	var (
+		params []Param
+		seen   = make(map[string]bool)
+	)
+	for _, spec := range specs {
+		var cur *Param
+		for _, token := range strings.Split(spec, ",") {
+			token = strings.TrimSpace(token)
+			if i := strings.Index(token, "="); i >= 0 {
+				name := strings.TrimSpace(token[:i])
+				if name == "" {
+					return nil, errors.Errorf("empty name in %q", spec)
+				} else if seen[name] {
+					return nil, errors.Errorf("%s is given more than once", name)
+				}
+				seen[name] = true
+				params = append(params, Param{Name: name})
+				cur = &params[len(params)-1]
+				token = strings.TrimSpace(token[i+1:])
+			} else if cur == nil {
+				return nil, errors.Errorf("expected <name>=<values> but got %q", spec)
+			}
+			if token == "" {
+				return nil, errors.Errorf("empty value for %s", cur.Name)
+			}
+			if strings.Contains(token, "..") {
+				if len(cur.Values) > 0 || cur.Range {
+					return nil, errors.Errorf("%s mixes a range with other values", cur.Name)
+				}
+				if err := parseRange(cur, token); err != nil {
+					return nil, err
+				}
+				continue
+			} else if cur.Range {
+				return nil, errors.Errorf("%s mixes a range with other values", cur.Name)
+			}
+			cur.Values = append(cur.Values, token)
+		}
+	}
+	return params, nil
+}
+
+func parseRange(p *Param, token string) error {
+	if strings.HasSuffix(token, ":log") {
+		p.Log = true
+		token = strings.TrimSuffix(token, ":log")
+	}
+	bounds := strings.SplitN(token, "..", 2)
+	min, err := strconv.ParseFloat(bounds[0], 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid range for %s", p.Name)
+	}
+	max, err := strconv.ParseFloat(bounds[1], 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid range for %s", p.Name)
+	}
+	if min > max {
+		return errors.Errorf("invalid range for %s: %v > %v", p.Name, min, max)
+	} else if p.Log && min <= 0 {
+		return errors.Errorf("invalid range for %s: the log scale needs positive bounds", p.Name)
+	}
+	_, errMin := strconv.Atoi(bounds[0])
+	_, errMax := strconv.Atoi(bounds[1])
+	p.Range, p.Min, p.Max, p.Int = true, min, max, errMin == nil && errMax == nil
+	return nil
+}
+
+// Configs returns the configurations for the search: all the combinations of the choices for
+// grid search, or trials of the random choices and samples by seed for random search.
+func Configs(typ SearchType, params []Param, trials int, seed int64) ([]Config, error) {
+	switch typ {
+	case Grid:
+		return GridConfigs(params)
+	case Random:
+		return RandomConfigs(params, trials, seed), nil
+	default:
+		return nil, invalidSearchTypeError(typ)
+	}
+}
+
+// GridConfigs returns all the combinations of the choices, where the last param changes fastest.
+func GridConfigs(params []Param) ([]Config, error) {
+	configs := []Config{{}}
+	for _, p := range params {
+		if p.Range {
+			return nil, errors.Errorf("the range of %s is only for %s search", p.Name, Random)
+		}
+		next := make([]Config, 0, len(configs)*len(p.Values))
+		for _, c := range configs {
+			for _, v := range p.Values {
+				next = append(next, append(append(Config(nil), c...), Setting{Name: p.Name, Value: v}))
+			}
+		}
+		configs = next
+	}
+	return configs, nil
+}
+
+// RandomConfigs returns trials configurations whose values are drawn independently by seed.
+func RandomConfigs(params []Param, trials int, seed int64) []Config {
+	rnd := rand.New(rand.NewSource(seed))
+	configs := make([]Config, trials)
+	for i := range configs {
+		c := make(Config, len(params))
+		for j, p := range params {
+			c[j] = Setting{Name: p.Name, Value: p.sample(rnd)}
+		}
+		configs[i] = c
+	}
+	return configs
+}
+
+func (p Param) sample(rnd *rand.Rand) string {
+	if !p.Range {
+		return p.Values[rnd.Intn(len(p.Values))]
+	}
+	var v float64
+	if p.Log {
+		v = math.Exp(math.Log(p.Min) + rnd.Float64()*(math.Log(p.Max)-math.Log(p.Min)))
+	} else {
+		v = p.Min + rnd.Float64()*(p.Max-p.Min)
+	}
+	if p.Int {
+		if p.Log {
+			v = math.Round(v)
+		} else {
+			// every integer in [Min, Max] is drawn by the same probability.
+			v = p.Min + float64(rnd.Intn(int(p.Max-p.Min)+1))
+		}
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
@@ -0,0 +1,86 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sweep
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParams(t *testing.T) {
+	params, err := ParseParams([]string{"dim=50,100,window=5,10", "initlr=0.001..0.1:log", "min-count=1..5"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Param{
+		{Name: "dim", Values: []string{"50", "100"}},
+		{Name: "window", Values: []string{"5", "10"}},
+		{Name: "initlr", Range: true, Min: 0.001, Max: 0.1, Log: true},
+		{Name: "min-count", Range: true, Min: 1, Max: 5, Int: true},
+	}, params)
+
+	for _, spec := range []string{
+		"50,100",
+		"dim=",
+		"dim=50,dim=100",
+		"dim=50,1..5",
+		"dim=1..5,50",
+		"dim=5..1",
+		"initlr=0..0.1:log",
+		"initlr=a..b",
+	} {
+		_, err := ParseParams([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestGridConfigs(t *testing.T) {
+	params, err := ParseParams([]string{"dim=50,100", "window=5,10,15"})
+	assert.NoError(t, err)
+	configs, err := Configs(Grid, params, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, configs, 6)
+	assert.Equal(t, Config{{Name: "dim", Value: "50"}, {Name: "window", Value: "5"}}, configs[0])
+	assert.Equal(t, Config{{Name: "dim", Value: "50"}, {Name: "window", Value: "10"}}, configs[1])
+	assert.Equal(t, Config{{Name: "dim", Value: "100"}, {Name: "window", Value: "15"}}, configs[5])
+
+	params, err = ParseParams([]string{"initlr=0.01..0.1"})
+	assert.NoError(t, err)
+	_, err = Configs(Grid, params, 0, 0)
+	assert.Error(t, err)
+}
+
+func TestRandomConfigs(t *testing.T) {
+	params, err := ParseParams([]string{"dim=50,100", "initlr=0.001..0.1:log", "window=1..3"})
+	assert.NoError(t, err)
+	configs, err := Configs(Random, params, 50, 1)
+	assert.NoError(t, err)
+	assert.Len(t, configs, 50)
+	windows := make(map[string]bool)
+	for _, c := range configs {
+		assert.Contains(t, []string{"50", "100"}, c[0].Value)
+		initlr, err := strconv.ParseFloat(c[1].Value, 64)
+		assert.NoError(t, err)
+		assert.True(t, 0.001 <= initlr && initlr <= 0.1, c[1].Value)
+		windows[c[2].Value] = true
+	}
+	assert.Equal(t, map[string]bool{"1": true, "2": true, "3": true}, windows)
+
+	again, _ := Configs(Random, params, 50, 1)
+	assert.Equal(t, configs, again)
+
+	_, err = Configs("bayes", params, 1, 1)
+	assert.Error(t, err)
+}
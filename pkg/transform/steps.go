@@ -0,0 +1,106 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+)
+
+type StepType = string
+
+const (
+	CenterStep    StepType = "center"
+	NormalizeStep StepType = "normalize"
+	// RemoveTopStep takes the number of components to remove, e.g. abtt=3.
+	RemoveTopStep StepType = "abtt"
+	// PCAStep takes the dimension to reduce, e.g. pca=100.
+	PCAStep StepType = "pca"
+)
+
+// Step is the transform with its argument.
+type Step struct {
+	Type StepType
+	Arg  int
+}
+
+func (s Step) String() string {
+	switch s.Type {
+	case RemoveTopStep, PCAStep:
+		return fmt.Sprintf("%s=%d", s.Type, s.Arg)
+	default:
+		return s.Type
+	}
+}
+
+// ParseSteps parses the comma separated steps, e.g. "center,abtt=2,pca=100,normalize".
+func ParseSteps(spec string) ([]Step, error) {
+	var steps []Step
+	for _, s := range strings.Split(spec, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		typ, arg, hasArg := strings.Cut(s, "=")
+		step := Step{Type: typ}
+		switch typ {
+		case CenterStep, NormalizeStep:
+			if hasArg {
+				return nil, errors.Errorf("step %s takes no argument: %s", typ, s)
+			}
+		case RemoveTopStep, PCAStep:
+			v, err := strconv.Atoi(arg)
+			if !hasArg || err != nil {
+				return nil, errors.Errorf("step %s requires an integer argument like %s=2: %s", typ, typ, s)
+			}
+			step.Arg = v
+		default:
+			return nil, errors.Errorf("invalid step: %s not in %s|%s|%s|%s", typ, CenterStep, NormalizeStep, RemoveTopStep, PCAStep)
+		}
+		steps = append(steps, step)
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("no steps to transform")
+	}
+	return steps, nil
+}
+
+// Apply applies the steps in order.
+func Apply(embs embedding.Embeddings, steps ...Step) (embedding.Embeddings, error) {
+	var err error
+	for _, step := range steps {
+		switch step.Type {
+		case CenterStep:
+			embs, err = Center(embs)
+		case NormalizeStep:
+			embs, err = Normalize(embs)
+		case RemoveTopStep:
+			embs, err = RemoveTop(embs, step.Arg)
+		case PCAStep:
+			embs, err = PCA(embs, step.Arg)
+		default:
+			err = errors.Errorf("invalid step: %s not in %s|%s|%s|%s", step.Type, CenterStep, NormalizeStep, RemoveTopStep, PCAStep)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to apply %s", step)
+		}
+	}
+	return embs, nil
+}
@@ -0,0 +1,174 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transform provides the post-processing for the trained embeddings.
+// Every transform returns the new embeddings and keeps the given ones as they are.
+package transform
+
+import (
+	"math"
+
+	"github.com/pkg/errors"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/embedding/embutil"
+	"github.com/ynqa/wego/pkg/util/linalg"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+// Center subtracts the mean vector from all vectors.
+func Center(embs embedding.Embeddings) (embedding.Embeddings, error) {
+	if err := validate(embs); err != nil {
+		return nil, err
+	}
+	res := clone(embs)
+	center(res)
+	return withNorm(res), nil
+}
+
+// Normalize scales all vectors to the unit length. The zero vectors are kept as they are.
+func Normalize(embs embedding.Embeddings) (embedding.Embeddings, error) {
+	if err := validate(embs); err != nil {
+		return nil, err
+	}
+	res := clone(embs)
+	for _, emb := range res {
+		if norm := vecmath.Norm(emb.Vector); norm > 0 {
+			vecmath.Scale(1/norm, emb.Vector)
+		}
+	}
+	return withNorm(res), nil
+}
+
+// RemoveTop centers the vectors and removes the projections on the top d principal
+// components, as in All-but-the-Top (Mu and Viswanath 2018).
+func RemoveTop(embs embedding.Embeddings, d int) (embedding.Embeddings, error) {
+	if err := validate(embs); err != nil {
+		return nil, err
+	}
+	if d < 0 || d >= embs[0].Dim {
+		return nil, errors.Errorf("number of components to remove must be in [0, %d) but got %d", embs[0].Dim, d)
+	}
+	res := clone(embs)
+	center(res)
+	components := principalComponents(res, d)
+	for _, emb := range res {
+		for _, c := range components {
+			vecmath.Axpy(-vecmath.Dot(c, emb.Vector), c, emb.Vector)
+		}
+	}
+	return withNorm(res), nil
+}
+
+// PCA centers the vectors and projects them on the top k principal components,
+// so that the dimension is reduced to k.
+func PCA(embs embedding.Embeddings, k int) (embedding.Embeddings, error) {
+	if err := validate(embs); err != nil {
+		return nil, err
+	}
+	if k <= 0 || k > embs[0].Dim {
+		return nil, errors.Errorf("dimension to reduce must be in [1, %d] but got %d", embs[0].Dim, k)
+	}
+	centered := clone(embs)
+	center(centered)
+	components := principalComponents(centered, k)
+	res := make(embedding.Embeddings, len(embs))
+	for i, emb := range centered {
+		vec := make([]float64, k)
+		for j, c := range components {
+			vec[j] = vecmath.Dot(c, emb.Vector)
+		}
+		res[i] = embedding.Embedding{
+			Word:   emb.Word,
+			Dim:    k,
+			Vector: vec,
+		}
+	}
+	return withNorm(res), nil
+}
+
+func validate(embs embedding.Embeddings) error {
+	if embs.Empty() {
+		return errors.New("no embeddings to transform")
+	}
+	return embs.Validate()
+}
+
+func clone(embs embedding.Embeddings) embedding.Embeddings {
+	res := make(embedding.Embeddings, len(embs))
+	for i, emb := range embs {
+		vec := make([]float64, len(emb.Vector))
+		copy(vec, emb.Vector)
+		res[i] = embedding.Embedding{
+			Word:   emb.Word,
+			Dim:    emb.Dim,
+			Vector: vec,
+		}
+	}
+	return res
+}
+
+func withNorm(embs embedding.Embeddings) embedding.Embeddings {
+	for i := range embs {
+		embs[i].Norm = embutil.Norm(embs[i].Vector)
+	}
+	return embs
+}
+
+func center(embs embedding.Embeddings) {
+	mean := make([]float64, embs[0].Dim)
+	for _, emb := range embs {
+		vecmath.Axpy(1, emb.Vector, mean)
+	}
+	vecmath.Scale(1/float64(len(embs)), mean)
+	for _, emb := range embs {
+		vecmath.Axpy(-1, mean, emb.Vector)
+	}
+}
+
+// principalComponents returns the top k eigenvectors of the covariance matrix of the centered embs.
+func principalComponents(embs embedding.Embeddings, k int) [][]float64 {
+	dim := embs[0].Dim
+	cov := make([][]float64, dim)
+	for i := range cov {
+		cov[i] = make([]float64, dim)
+	}
+	for _, emb := range embs {
+		vec := emb.Vector
+		for i := 0; i < dim; i++ {
+			vecmath.Axpy(vec[i], vec[:i+1], cov[i][:i+1])
+		}
+	}
+	for i := 0; i < dim; i++ {
+		for j := 0; j <= i; j++ {
+			cov[i][j] /= float64(len(embs))
+			cov[j][i] = cov[i][j]
+		}
+	}
+	_, vectors := linalg.SymEigen(cov)
+	components := vectors[:k]
+	// fix the sign, so that the result doesn't depend on the solver.
+	for _, c := range components {
+		var max float64
+		for _, v := range c {
+			if math.Abs(v) > math.Abs(max) {
+				max = v
+			}
+		}
+		if max < 0 {
+			vecmath.Scale(-1, c)
+		}
+	}
+	return components
+}
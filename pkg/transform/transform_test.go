@@ -0,0 +1,130 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transform
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/embedding"
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+// testEmbeddings returns the vectors which mostly spread along (1, 1, 0, 0) around (5, 5, 5, 5).
+func testEmbeddings(t *testing.T) embedding.Embeddings {
+	rnd := rand.New(rand.NewSource(1))
+	var b strings.Builder
+	for i := 0; i < 50; i++ {
+		x := rnd.NormFloat64() * 10
+		fmt.Fprintf(&b, "w%d %f %f %f %f\n", i, 5+x+rnd.NormFloat64(), 5+x+rnd.NormFloat64(), 5+rnd.NormFloat64(), 5+rnd.NormFloat64())
+	}
+	embs, err := embedding.Load(strings.NewReader(b.String()))
+	assert.NoError(t, err)
+	return embs
+}
+
+func mean(embs embedding.Embeddings) []float64 {
+	res := make([]float64, embs[0].Dim)
+	for _, emb := range embs {
+		vecmath.Axpy(1/float64(len(embs)), emb.Vector, res)
+	}
+	return res
+}
+
+func TestCenter(t *testing.T) {
+	embs := testEmbeddings(t)
+	res, err := Center(embs)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0, 0, 0, 0}, mean(res), 1e-9)
+	assert.NotEqual(t, embs[0].Vector, res[0].Vector)
+}
+
+func TestNormalize(t *testing.T) {
+	embs, err := embedding.Load(strings.NewReader("a 3 4\nb 0 0\n"))
+	assert.NoError(t, err)
+	res, err := Normalize(embs)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{0.6, 0.8}, res[0].Vector, 1e-9)
+	assert.InDelta(t, 1, res[0].Norm, 1e-9)
+	assert.Equal(t, []float64{0, 0}, res[1].Vector)
+}
+
+func TestRemoveTop(t *testing.T) {
+	res, err := RemoveTop(testEmbeddings(t), 1)
+	assert.NoError(t, err)
+	top := []float64{1, 1, 0, 0}
+	for _, emb := range res {
+		assert.InDelta(t, 0, vecmath.Dot(top, emb.Vector)/vecmath.Norm(top), 1)
+	}
+	assert.InDeltaSlice(t, []float64{0, 0, 0, 0}, mean(res), 1e-9)
+
+	_, err = RemoveTop(res, 4)
+	assert.Error(t, err)
+}
+
+func TestPCA(t *testing.T) {
+	embs := testEmbeddings(t)
+	res, err := PCA(embs, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, res[0].Dim)
+	assert.Len(t, res[0].Vector, 2)
+
+	// the projection on all components keeps the distances between the vectors.
+	full, err := PCA(embs, 4)
+	assert.NoError(t, err)
+	d1, d2 := make([]float64, 4), make([]float64, 4)
+	copy(d1, embs[0].Vector)
+	vecmath.Axpy(-1, embs[1].Vector, d1)
+	copy(d2, full[0].Vector)
+	vecmath.Axpy(-1, full[1].Vector, d2)
+	assert.InDelta(t, vecmath.Norm(d1), vecmath.Norm(d2), 1e-6)
+
+	_, err = PCA(embs, 0)
+	assert.Error(t, err)
+}
+
+func TestParseSteps(t *testing.T) {
+	steps, err := ParseSteps("center, abtt=2,pca=3,normalize")
+	assert.NoError(t, err)
+	assert.Equal(t, []Step{
+		{Type: CenterStep},
+		{Type: RemoveTopStep, Arg: 2},
+		{Type: PCAStep, Arg: 3},
+		{Type: NormalizeStep},
+	}, steps)
+
+	for _, spec := range []string{"", "center=1", "pca", "pca=x", "whiten"} {
+		_, err := ParseSteps(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestApply(t *testing.T) {
+	steps, err := ParseSteps("abtt=1,pca=2,normalize")
+	assert.NoError(t, err)
+	res, err := Apply(testEmbeddings(t), steps...)
+	assert.NoError(t, err)
+	for _, emb := range res {
+		assert.Equal(t, 2, emb.Dim)
+		assert.InDelta(t, 1, emb.Norm, 1e-9)
+	}
+
+	_, err = Apply(testEmbeddings(t), Step{Type: PCAStep, Arg: 5})
+	assert.EqualError(t, err, "failed to apply pca=5: dimension to reduce must be in [1, 4] but got 5")
+}
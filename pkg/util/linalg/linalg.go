@@ -0,0 +1,164 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package linalg provides the small dense linear algebra routines for the count-based
+// models and the embedding transforms, in pure Go.
+package linalg
+
+import (
+	"math"
+	"sort"
+
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+const (
+	jacobiMaxSweep = 100
+	jacobiEps      = 1e-12
+)
+
+// SymEigen computes the eigen decomposition of the symmetric matrix a by the cyclic
+// Jacobi method. It returns the eigenvalues in descending order and the corresponding
+// eigenvectors, where vectors[i] is the eigenvector for values[i]. a is not modified.
+func SymEigen(a [][]float64) ([]float64, [][]float64) {
+	n := len(a)
+	m := make([][]float64, n)
+	v := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+		copy(m[i], a[i])
+		v[i] = make([]float64, n)
+		v[i][i] = 1
+	}
+
+	for sweep := 0; sweep < jacobiMaxSweep; sweep++ {
+		var off, norm float64
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if i != j {
+					off += m[i][j] * m[i][j]
+				}
+				norm += m[i][j] * m[i][j]
+			}
+		}
+		if off <= jacobiEps*jacobiEps*norm {
+			break
+		}
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := 1 / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				if theta < 0 {
+					t = -t
+				}
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				rotate(m, v, p, q, c, s)
+			}
+		}
+	}
+
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = m[i][i]
+	}
+	// v holds the eigenvectors in its columns.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return values[order[i]] > values[order[j]]
+	})
+	sorted, vectors := make([]float64, n), make([][]float64, n)
+	for k, i := range order {
+		sorted[k] = values[i]
+		vectors[k] = make([]float64, n)
+		for j := 0; j < n; j++ {
+			vectors[k][j] = v[j][i]
+		}
+	}
+	return sorted, vectors
+}
+
+// rotate applies the Jacobi rotation on (p, q) to m from both sides, and accumulates it into v.
+func rotate(m, v [][]float64, p, q int, c, s float64) {
+	n := len(m)
+	for k := 0; k < n; k++ {
+		mkp, mkq := m[k][p], m[k][q]
+		m[k][p], m[k][q] = c*mkp-s*mkq, s*mkp+c*mkq
+	}
+	for k := 0; k < n; k++ {
+		mpk, mqk := m[p][k], m[q][k]
+		m[p][k], m[q][k] = c*mpk-s*mqk, s*mpk+c*mqk
+	}
+	for k := 0; k < n; k++ {
+		vkp, vkq := v[k][p], v[k][q]
+		v[k][p], v[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+	}
+}
+
+// Orthonormalize makes the rows of a orthonormal in place by the modified Gram-Schmidt
+// process with reorthogonalization. The rows which are linearly dependent on the
+// previous ones are set to zero.
+func Orthonormalize(a [][]float64) {
+	for i := range a {
+		for pass := 0; pass < 2; pass++ {
+			for j := 0; j < i; j++ {
+				vecmath.Axpy(-vecmath.Dot(a[j], a[i]), a[j], a[i])
+			}
+		}
+		norm := vecmath.Norm(a[i])
+		if norm < 1e-10 {
+			vecmath.Zero(a[i])
+			continue
+		}
+		vecmath.Scale(1/norm, a[i])
+	}
+}
+
+// SVD computes the singular value decomposition a = u diag(s) vᵀ of the m×n matrix a
+// with m <= n through the eigen decomposition of a aᵀ. It returns the singular values
+// in descending order, u[i] as the i-th left singular vector (length m) and v[i] as the
+// i-th right singular vector (length n). It is suited to the small matrices.
+func SVD(a [][]float64) ([]float64, [][]float64, [][]float64) {
+	m := len(a)
+	gram := make([][]float64, m)
+	for i := range gram {
+		gram[i] = make([]float64, m)
+		for j := 0; j <= i; j++ {
+			gram[i][j] = vecmath.Dot(a[i], a[j])
+			gram[j][i] = gram[i][j]
+		}
+	}
+	values, u := SymEigen(gram)
+
+	s, v := make([]float64, m), make([][]float64, m)
+	for k := range values {
+		if values[k] > 0 {
+			s[k] = math.Sqrt(values[k])
+		}
+		v[k] = make([]float64, len(a[0]))
+		if s[k] == 0 {
+			continue
+		}
+		for i := 0; i < m; i++ {
+			vecmath.Axpy(u[k][i]/s[k], a[i], v[k])
+		}
+	}
+	return s, u, v
+}
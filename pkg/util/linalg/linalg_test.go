@@ -0,0 +1,71 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linalg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ynqa/wego/pkg/util/vecmath"
+)
+
+func TestSymEigen(t *testing.T) {
+	a := [][]float64{
+		{2, 1, 0},
+		{1, 2, 0},
+		{0, 0, 5},
+	}
+	values, vectors := SymEigen(a)
+	assert.InDeltaSlice(t, []float64{5, 3, 1}, values, 1e-9)
+	for k, vec := range vectors {
+		for i := range a {
+			// a v = λ v
+			assert.InDelta(t, values[k]*vec[i], vecmath.Dot(a[i], vec), 1e-9)
+		}
+	}
+}
+
+func TestOrthonormalize(t *testing.T) {
+	a := [][]float64{
+		{1, 1, 0},
+		{1, 0, 1},
+		{2, 1, 1},
+	}
+	Orthonormalize(a)
+	assert.InDelta(t, 1, vecmath.Dot(a[0], a[0]), 1e-9)
+	assert.InDelta(t, 1, vecmath.Dot(a[1], a[1]), 1e-9)
+	assert.InDelta(t, 0, vecmath.Dot(a[0], a[1]), 1e-9)
+	// the third row is the sum of the first two.
+	assert.Equal(t, []float64{0, 0, 0}, a[2])
+}
+
+func TestSVD(t *testing.T) {
+	a := [][]float64{
+		{3, 0, 0, 0},
+		{0, 0, 2, 0},
+	}
+	s, u, v := SVD(a)
+	assert.InDeltaSlice(t, []float64{3, 2}, s, 1e-9)
+	for i := range a {
+		for j := range a[i] {
+			var rec float64
+			for k := range s {
+				rec += u[k][i] * s[k] * v[k][j]
+			}
+			assert.InDelta(t, a[i][j], rec, 1e-9)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vecmath provides the vector kernels for the training and search hot loops.
+// The loops are unrolled by four, and the slices are resliced to the same length
+// in advance so that the compiler can drop bounds checks.
+package vecmath
+
+import (
+	"math"
+
+	"github.com/ynqa/wego/pkg/util/num"
+)
+
+// Dot returns the inner product of x and y. y must be at least as long as x.
+// It accumulates in float64 for the precision of float32 vectors.
+func Dot[T num.Float](x, y []T) T {
+	return T(dot(x, y))
+}
+
+func dot[T num.Float](x, y []T) float64 {
+	y = y[:len(x)]
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(x); i += 4 {
+		xx, yy := x[i:i+4:i+4], y[i:i+4:i+4]
+		s0 += float64(xx[0]) * float64(yy[0])
+		s1 += float64(xx[1]) * float64(yy[1])
+		s2 += float64(xx[2]) * float64(yy[2])
+		s3 += float64(xx[3]) * float64(yy[3])
+	}
+	for ; i < len(x); i++ {
+		s0 += float64(x[i]) * float64(y[i])
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// Axpy computes y += a*x. y must be at least as long as x.
+func Axpy[T num.Float](a T, x, y []T) {
+	y = y[:len(x)]
+	i := 0
+	for ; i+4 <= len(x); i += 4 {
+		xx, yy := x[i:i+4:i+4], y[i:i+4:i+4]
+		yy[0] += a * xx[0]
+		yy[1] += a * xx[1]
+		yy[2] += a * xx[2]
+		yy[3] += a * xx[3]
+	}
+	for ; i < len(x); i++ {
+		y[i] += a * x[i]
+	}
+}
+
+// CrossAxpy computes x += a*y and y += a*x at once, that is, both updates use
+// the values before the update. y must be at least as long as x.
+func CrossAxpy[T num.Float](a T, x, y []T) {
+	y = y[:len(x)]
+	i := 0
+	for ; i+4 <= len(x); i += 4 {
+		xx, yy := x[i:i+4:i+4], y[i:i+4:i+4]
+		xx[0], yy[0] = xx[0]+a*yy[0], yy[0]+a*xx[0]
+		xx[1], yy[1] = xx[1]+a*yy[1], yy[1]+a*xx[1]
+		xx[2], yy[2] = xx[2]+a*yy[2], yy[2]+a*xx[2]
+		xx[3], yy[3] = xx[3]+a*yy[3], yy[3]+a*xx[3]
+	}
+	for ; i < len(x); i++ {
+		x[i], y[i] = x[i]+a*y[i], y[i]+a*x[i]
+	}
+}
+
+// Scale computes x *= a.
+func Scale[T num.Float](a T, x []T) {
+	i := 0
+	for ; i+4 <= len(x); i += 4 {
+		xx := x[i : i+4 : i+4]
+		xx[0] *= a
+		xx[1] *= a
+		xx[2] *= a
+		xx[3] *= a
+	}
+	for ; i < len(x); i++ {
+		x[i] *= a
+	}
+}
+
+// Zero sets all elements of x to zero.
+func Zero[T num.Float](x []T) {
+	for i := range x {
+		x[i] = 0
+	}
+}
+
+// Norm returns the euclidean norm of x.
+func Norm[T num.Float](x []T) float64 {
+	return math.Sqrt(dot(x, x))
+}
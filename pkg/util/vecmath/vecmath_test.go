@@ -0,0 +1,138 @@
+// Copyright © 2020 wego authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vecmath
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDot(t *testing.T) {
+	testCases := []struct {
+		name   string
+		x      []float64
+		y      []float64
+		expect float64
+	}{
+		{
+			name:   "empty",
+			expect: 0,
+		},
+		{
+			name:   "shorter than unroll",
+			x:      []float64{1, 2, 3},
+			y:      []float64{4, 5, 6},
+			expect: 32,
+		},
+		{
+			name:   "unroll with remainder",
+			x:      []float64{1, 2, 3, 4, 5, 6},
+			y:      []float64{1, 1, 1, 1, 2, 2},
+			expect: 32,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, Dot(tc.x, tc.y))
+		})
+	}
+
+	// 1e8+1 is rounded to 1e8 in float32.
+	assert.Equal(t, float32(1), Dot([]float32{1e8, 1, -1e8}, []float32{1, 1, 1}))
+}
+
+func TestAxpy(t *testing.T) {
+	x := []float32{1, 2, 3, 4, 5}
+	y := []float32{1, 1, 1, 1, 1}
+	Axpy(2, x, y)
+	assert.Equal(t, []float32{3, 5, 7, 9, 11}, y)
+}
+
+func TestCrossAxpy(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	y := []float64{5, 4, 3, 2, 1}
+	CrossAxpy(-1, x, y)
+	assert.Equal(t, []float64{-4, -2, 0, 2, 4}, x)
+	assert.Equal(t, []float64{4, 2, 0, -2, -4}, y)
+}
+
+func TestScale(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	Scale(0.5, x)
+	assert.Equal(t, []float64{0.5, 1, 1.5, 2, 2.5}, x)
+}
+
+func TestNorm(t *testing.T) {
+	assert.Equal(t, 5., Norm([]float64{3, 4}))
+	assert.Equal(t, 0., Norm([]float32{}))
+	// the squares overflow float32.
+	assert.InEpsilon(t, math.Sqrt2*1e20, Norm([]float32{1e20, 1e20}), 1e-6)
+}
+
+var sink float32
+
+func randVector(dim int) []float32 {
+	vec := make([]float32, dim)
+	for i := range vec {
+		vec[i] = rand.Float32()
+	}
+	return vec
+}
+
+func naiveDot(x, y []float32) float32 {
+	var s float64
+	for i := 0; i < len(x); i++ {
+		s += float64(x[i]) * float64(y[i])
+	}
+	return float32(s)
+}
+
+func BenchmarkNaiveDot(b *testing.B) {
+	x, y := randVector(100), randVector(100)
+	for i := 0; i < b.N; i++ {
+		sink += naiveDot(x, y)
+	}
+}
+
+func BenchmarkDot(b *testing.B) {
+	x, y := randVector(100), randVector(100)
+	for i := 0; i < b.N; i++ {
+		sink += Dot(x, y)
+	}
+}
+
+func naiveAxpy(a float32, x, y []float32) {
+	for i := 0; i < len(x); i++ {
+		y[i] += a * x[i]
+	}
+}
+
+func BenchmarkNaiveAxpy(b *testing.B) {
+	x, y := randVector(100), randVector(100)
+	for i := 0; i < b.N; i++ {
+		naiveAxpy(1e-6, x, y)
+	}
+}
+
+func BenchmarkAxpy(b *testing.B) {
+	x, y := randVector(100), randVector(100)
+	for i := 0; i < b.N; i++ {
+		Axpy(1e-6, x, y)
+	}
+}
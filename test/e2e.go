@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -159,10 +160,10 @@ func e2e(mod model.Model) error {
 	if err != nil {
 		log.Fatal(err)
 	}
-	if err := mod.Train(input); err != nil {
+	if err := mod.Train(context.Background(), input); err != nil {
 		return err
 	}
-	if err := mod.Save(output, vector.Agg); err != nil {
+	if err := mod.Save(output, vector.Word); err != nil {
 		return err
 	}
 
@@ -17,43 +17,11 @@ package main
 import (
 	"os"
 
-	"github.com/pkg/errors"
-	"github.com/spf13/cobra"
-
-	"github.com/ynqa/wego/cmd/model/glove"
-	"github.com/ynqa/wego/cmd/model/lexvec"
-	"github.com/ynqa/wego/cmd/model/word2vec"
-	"github.com/ynqa/wego/cmd/query"
-	"github.com/ynqa/wego/cmd/query/console"
+	"github.com/ynqa/wego/cmd"
 )
 
 func main() {
-	word2vec := word2vec.New()
-	glove := glove.New()
-	lexvec := lexvec.New()
-	query := query.New()
-	console := console.New()
-
-	cmd := &cobra.Command{
-		Use:   "wego",
-		Short: "tools for embedding words into vector space",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return errors.Errorf("Set sub-command. One of %s|%s|%s|%s|%s",
-				word2vec.Name(),
-				glove.Name(),
-				lexvec.Name(),
-				query.Name(),
-				console.Name(),
-			)
-		},
-	}
-	cmd.AddCommand(word2vec)
-	cmd.AddCommand(glove)
-	cmd.AddCommand(lexvec)
-	cmd.AddCommand(query)
-	cmd.AddCommand(console)
-
-	if err := cmd.Execute(); err != nil {
+	if err := cmd.New().Execute(); err != nil {
 		os.Exit(1)
 	}
 }